@@ -3,6 +3,7 @@ package main
 import (
 	"api-layer/config"
 	authHandlers "api-layer/handlers/auth"
+	"api-layer/handlers/moderation"
 	"api-layer/handlers/search"
 	"api-layer/handlers/wiki"
 	"api-layer/middleware"
@@ -24,18 +25,42 @@ func main() {
 	r.GET("/v1/wiki/pages/:id", wiki.GetPage)
 	r.GET("/v1/wiki/pages/:id/revisions", wiki.GetPageRevisions)
 	r.GET("/v1/wiki/pages/:id/revisions/:rev", wiki.GetPageRevision)
+	r.GET("/v1/wiki/pages/:id/diff", wiki.GetPageDiff)
+	r.GET("/v1/wiki/pages/:id/blame", wiki.GetPageBlame)
 	r.GET("/v1/wiki/indexable-pages", wiki.GetIndexablePages)
+	r.GET("/v1/wiki/indexable-pages/:id", wiki.GetIndexablePage)
 	r.GET("/v1/wiki/categories", wiki.GetCategories)
 	r.GET("/v1/wiki/pages/:id/categories", wiki.GetPageCategories)
+	r.GET("/v1/wiki/pages/:id/redirects", wiki.GetPageRedirects)
+	r.GET("/v1/wiki/pages/:id/backlinks", wiki.GetPageBacklinks)
+	r.GET("/v1/wiki/pages/:id/protection", wiki.GetPageProtection)
 	r.GET("/v1/wiki/revisions", wiki.GetRevisionsByAuthor)
+	r.GET("/v1/wiki/recent-changes", wiki.GetRecentChanges)
 
 	// Protected endpoints - require valid token and contributor role
 	protected := r.Group("/v1/wiki")
 	protected.Use(middleware.AuthMiddleware(), middleware.RequireRole("contributor"))
 	{
 		protected.POST("/pages/new", wiki.PostNewPage)
-		protected.POST("/pages/:id/revisions", wiki.PostPageRevision)
-		protected.POST("/pages/:id/categories", wiki.PostPageCategories)
+		protected.POST("/pages/:id/revisions", middleware.RequireEditable(), wiki.PostPageRevision)
+		protected.POST("/pages/:id/revisions/:rev/revert", middleware.RequireEditable(), wiki.PostRevertRevision)
+		protected.POST("/pages/:id/categories", middleware.RequireEditable(), wiki.PostPageCategories)
+		protected.GET("/drafts", wiki.GetDrafts)
+		protected.GET("/pages/:id/draft", wiki.GetPageDraft)
+		protected.POST("/pages/:id/draft", wiki.PostPageDraft)
+		protected.POST("/pages/:id/draft/delete", wiki.PostDeletePageDraft)
+	}
+
+	// Watchlists and notifications - require a valid token, for any user
+	signedIn := r.Group("/v1/wiki")
+	signedIn.Use(middleware.AuthMiddleware())
+	{
+		signedIn.GET("/watches", wiki.GetWatches)
+		signedIn.POST("/watches", wiki.PostWatch)
+		signedIn.POST("/watches/:id/delete", wiki.PostDeleteWatch)
+		signedIn.GET("/notifications", wiki.GetNotifications)
+		signedIn.GET("/notifications/unread-count", wiki.GetUnreadCount)
+		signedIn.POST("/notifications/read", wiki.PostNotificationsRead)
 	}
 
 	// Moderator-only endpoints - require valid token and moderator role
@@ -43,6 +68,34 @@ func main() {
 	moderator.Use(middleware.AuthMiddleware(), middleware.RequireRole("moderator"))
 	{
 		moderator.POST("/pages/:id/delete", wiki.PostDeletePage)
+		moderator.POST("/pages/:id/restore", wiki.PostRestorePage)
+		moderator.GET("/deleted-pages", wiki.GetDeletedPages)
+		moderator.POST("/pages/:id/redirects", wiki.PostPageAlias)
+		moderator.POST("/pages/:id/redirects/:slug/delete", wiki.PostDeleteRedirect)
+		moderator.GET("/reports/:report", wiki.GetReport)
+		moderator.GET("/protected-pages", wiki.GetProtectedPages)
+		moderator.POST("/pages/:id/protection", wiki.PostPageProtection)
+	}
+
+	// Review queue for edits held by the moderation service
+	modQueue := r.Group("/v1/moderation")
+	modQueue.Use(middleware.AuthMiddleware(), middleware.RequireRole("moderator"))
+	{
+		modQueue.GET("/pending", moderation.GetPendingList)
+		modQueue.GET("/pending/:id", moderation.GetPending)
+		modQueue.GET("/pending/:id/diff", moderation.GetPendingDiff)
+		modQueue.POST("/pending/:id/approve", moderation.PostApprovePending)
+		modQueue.POST("/pending/:id/reject", moderation.PostRejectPending)
+		modQueue.GET("/sensitive-pages", moderation.GetSensitivePages)
+		modQueue.POST("/sensitive-pages", moderation.PostSensitivePage)
+		modQueue.POST("/sensitive-pages/:id/delete", moderation.PostDeleteSensitivePage)
+	}
+
+	// Admin-only endpoints - require valid token and admin role
+	admin := r.Group("/v1/wiki")
+	admin.Use(middleware.AuthMiddleware(), middleware.RequireRole("admin"))
+	{
+		admin.GET("/export/git", wiki.GetExportGit)
 	}
 
 	r.GET("/v1/search/search", search.SearchRequest)
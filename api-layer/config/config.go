@@ -10,12 +10,14 @@ var WikiServiceURL string
 var SearchServiceURL string
 var ImageServiceURL string
 var AuthServiceURL string
+var ModerationServiceURL string
 
 func init() {
 	godotenv.Load()
 	WikiServiceURL = GetEnv("WIKI_SERVICE_URL", "http://127.0.0.1:9454")
 	SearchServiceURL = GetEnv("SEARCH_SERVICE_URL", "http://127.0.0.1:7724")
 	AuthServiceURL = GetEnv("AUTH_SERVICE_URL", "http://127.0.0.1:8083")
+	ModerationServiceURL = GetEnv("MODERATION_SERVICE_URL", "http://127.0.0.1:6633")
 }
 
 // Note: To use external URLs for auto-start functionality, set these env vars:
@@ -0,0 +1,48 @@
+package moderation
+
+import (
+	"api-layer/config"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// get fetches from the moderation service and passes its response on.
+func get(c *gin.Context, modURL string) {
+	res, err := http.Get(modURL)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "moderation service unreachable", "detail": err.Error()})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetPendingList(c *gin.Context) {
+	modURL := fmt.Sprintf("%s/pending", config.ModerationServiceURL)
+	if c.Request.URL.RawQuery != "" {
+		modURL += "?" + c.Request.URL.RawQuery
+	}
+	get(c, modURL)
+}
+
+func GetPending(c *gin.Context) {
+	get(c, fmt.Sprintf("%s/pending/%s", config.ModerationServiceURL, c.Param("id")))
+}
+
+func GetPendingDiff(c *gin.Context) {
+	get(c, fmt.Sprintf("%s/pending/%s/diff", config.ModerationServiceURL, c.Param("id")))
+}
+
+func GetSensitivePages(c *gin.Context) {
+	get(c, fmt.Sprintf("%s/sensitive-pages", config.ModerationServiceURL))
+}
@@ -0,0 +1,83 @@
+package moderation
+
+import (
+	"api-layer/config"
+	"api-layer/middleware"
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// post sends fields to the moderation service and passes its response on.
+func post(c *gin.Context, modURL string, fields map[string]string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, modURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "moderation service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+// PostApprovePending applies a held edit. The reviewer is the moderator
+// making the request, taken from their token rather than the form, and the
+// edit is checked against the page's protection as theirs, so their roles
+// go with it.
+func PostApprovePending(c *gin.Context) {
+	roles, accountCreated := middleware.Editor(c)
+	post(c, fmt.Sprintf("%s/pending/%s/approve", config.ModerationServiceURL, c.Param("id")), map[string]string{
+		"reviewer":        c.GetString("email"),
+		"roles":           roles,
+		"account_created": accountCreated,
+	})
+}
+
+// PostRejectPending rejects a held edit, with the moderator making the
+// request as its reviewer.
+func PostRejectPending(c *gin.Context) {
+	post(c, fmt.Sprintf("%s/pending/%s/reject", config.ModerationServiceURL, c.Param("id")), map[string]string{
+		"reviewer": c.GetString("email"),
+		"reason":   c.PostForm("reason"),
+	})
+}
+
+func PostSensitivePage(c *gin.Context) {
+	post(c, fmt.Sprintf("%s/sensitive-pages", config.ModerationServiceURL), map[string]string{
+		"page_id": c.PostForm("page_id"),
+		"note":    c.PostForm("note"),
+		"user":    c.GetString("email"),
+	})
+}
+
+func PostDeleteSensitivePage(c *gin.Context) {
+	post(c, fmt.Sprintf("%s/sensitive-pages/%s/delete", config.ModerationServiceURL, c.Param("id")), nil)
+}
@@ -0,0 +1,22 @@
+package search
+
+import (
+	"api-layer/config"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// IndexPage asks the search service to (re)index a single page.
+func IndexPage(id string) error {
+	resp, err := http.Post(fmt.Sprintf("%s/index/%s", config.SearchServiceURL, url.PathEscape(id)), "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("search service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
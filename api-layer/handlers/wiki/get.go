@@ -2,6 +2,7 @@ package wiki
 
 import (
 	"api-layer/config"
+	"api-layer/middleware"
 	"fmt"
 	"io"
 	"net/http"
@@ -42,7 +43,7 @@ func GetPages(c *gin.Context) {
 
 func GetPage(c *gin.Context) {
 	id := c.Param("id")
-	res, err := http.Get(fmt.Sprintf("%s/pages/%s", config.WikiServiceURL, id))
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s?%s", config.WikiServiceURL, id, c.Request.URL.RawQuery))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pages."})
 		return
@@ -105,6 +106,61 @@ func GetPageRevision(c *gin.Context) {
 	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
 }
 
+func GetPageDiff(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s/diff?%s", config.WikiServiceURL, id, c.Request.URL.RawQuery))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch diff"})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetPageBlame(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s/blame?%s", config.WikiServiceURL, id, c.Request.URL.RawQuery))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch blame"})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+// GetExportGit streams the wiki's history as a git fast-import stream.
+// The export can be large, so it's copied through rather than buffered.
+func GetExportGit(c *gin.Context) {
+	res, err := http.Get(fmt.Sprintf("%s/export/git?%s", config.WikiServiceURL, c.Request.URL.RawQuery))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable"})
+		return
+	}
+	defer res.Body.Close()
+
+	for k, vals := range res.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	c.Status(res.StatusCode)
+	io.Copy(c.Writer, res.Body)
+}
+
 func GetIndexablePages(c *gin.Context) {
 	ind := c.DefaultQuery("index", "0")
 	count := c.DefaultQuery("count", "10")
@@ -143,6 +199,77 @@ func GetCategories(c *gin.Context) {
 	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
 }
 
+func GetPageRedirects(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s/redirects", config.WikiServiceURL, id))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch page redirects."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetPageProtection(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s/protection", config.WikiServiceURL, id))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch page protection."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetProtectedPages(c *gin.Context) {
+	res, err := http.Get(fmt.Sprintf("%s/protected-pages", config.WikiServiceURL))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch protected pages."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetPageBacklinks(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/pages/%s/backlinks", config.WikiServiceURL, id))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch backlinks."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
 func GetPageCategories(c *gin.Context) {
 	id := c.Param("id")
 	res, err := http.Get(fmt.Sprintf("%s/pages/%s/categories", config.WikiServiceURL, id))
@@ -193,3 +320,131 @@ func GetRevisionsByAuthor(c *gin.Context) {
 
 	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
 }
+
+func GetIndexablePage(c *gin.Context) {
+	id := c.Param("id")
+	res, err := http.Get(fmt.Sprintf("%s/indexable-pages/%s", config.WikiServiceURL, id))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch page."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetDeletedPages(c *gin.Context) {
+	ind, err := strconv.Atoi(c.DefaultQuery("index", "0"))
+	if err != nil {
+		ind = 0
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil {
+		count = 10
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/deleted-pages?index=%d&count=%d", config.WikiServiceURL, ind, count))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch deleted pages."})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetReport(c *gin.Context) {
+	report := c.Param("report")
+	res, err := http.Get(fmt.Sprintf("%s/reports/%s?%s", config.WikiServiceURL, report, c.Request.URL.RawQuery))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch report"})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+// getAsUser fetches from the wiki service on behalf of the authenticated
+// user, for routes like drafts and watches that only show the user's own
+// data.
+func getAsUser(c *gin.Context, wikiURL string) {
+	req, err := http.NewRequest(http.MethodGet, wikiURL, nil)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("X-User-Email", c.GetString("email"))
+	req.Header.Set("X-User-ID", middleware.UserID(c))
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetDrafts(c *gin.Context) {
+	getAsUser(c, fmt.Sprintf("%s/drafts", config.WikiServiceURL))
+}
+
+func GetPageDraft(c *gin.Context) {
+	getAsUser(c, fmt.Sprintf("%s/pages/%s/draft", config.WikiServiceURL, c.Param("id")))
+}
+
+func GetRecentChanges(c *gin.Context) {
+	res, err := http.Get(fmt.Sprintf("%s/recent-changes?%s", config.WikiServiceURL, c.Request.URL.RawQuery))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch recent changes"})
+		return
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read response"})
+		return
+	}
+
+	c.Data(res.StatusCode, res.Header.Get("Content-Type"), body)
+}
+
+func GetWatches(c *gin.Context) {
+	getAsUser(c, fmt.Sprintf("%s/watches", config.WikiServiceURL))
+}
+
+func GetNotifications(c *gin.Context) {
+	getAsUser(c, fmt.Sprintf("%s/notifications?%s", config.WikiServiceURL, c.Request.URL.RawQuery))
+}
+
+func GetUnreadCount(c *gin.Context) {
+	getAsUser(c, fmt.Sprintf("%s/notifications/unread-count", config.WikiServiceURL))
+}
@@ -2,11 +2,16 @@ package wiki
 
 import (
 	"api-layer/config"
+	"api-layer/handlers/search"
+	"api-layer/middleware"
 	"bytes"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -137,9 +142,11 @@ func PostDeletePage(c *gin.Context) {
 	io.Copy(c.Writer, resp.Body)
 }
 
+// PostPageRevision sends edits through the moderation service, which
+// applies them or holds them for review depending on the editor's roles.
+// A held edit gets a 202 with the pending edit.
 func PostPageRevision(c *gin.Context) {
-	id := c.Param("id")
-	wikiURL := fmt.Sprintf("%s/pages/%s/revisions", config.WikiServiceURL, id)
+	modURL := fmt.Sprintf("%s/revisions", config.ModerationServiceURL)
 
 	// get data from request
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
@@ -164,16 +171,23 @@ func PostPageRevision(c *gin.Context) {
 	}
 	defer file.Close()
 
-	// create new request to wiki service
+	// create new request to moderation service
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 
-	writer.WriteField("page_id", c.PostForm("page_id"))
+	// the page is the one in the route, whose protection was checked
+	writer.WriteField("page_id", c.Param("id"))
 	writer.WriteField("author", c.PostForm("author"))
 
 	writer.WriteField("slug", c.PostForm("slug"))
 	writer.WriteField("name", c.PostForm("name"))
 	writer.WriteField("archive_date", c.PostForm("archive_date"))
+	writer.WriteField("base_revision", c.PostForm("base_revision"))
+	writer.WriteField("summary", c.PostForm("summary"))
+	writer.WriteField("minor", c.PostForm("minor"))
+	roles, accountCreated := middleware.Editor(c)
+	writer.WriteField("roles", roles)
+	writer.WriteField("account_created", accountCreated)
 
 	dstPart, err := writer.CreateFormFile("new_content", fileHeader.Filename)
 	if err != nil {
@@ -193,7 +207,7 @@ func PostPageRevision(c *gin.Context) {
 		return
 	}
 
-	req, err := http.NewRequest(http.MethodPost, wikiURL, &body)
+	req, err := http.NewRequest(http.MethodPost, modURL, &body)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
 		return
@@ -204,7 +218,7 @@ func PostPageRevision(c *gin.Context) {
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		c.JSON(http.StatusBadGateway, gin.H{"error": "moderation service unreachable", "detail": err.Error()})
 		return
 	}
 	defer resp.Body.Close()
@@ -234,6 +248,8 @@ func PostPageCategories(c *gin.Context) {
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
+	// watchers of the page hear about the change, other than this user
+	req.Header.Set("X-User-Email", c.GetString("email"))
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -245,3 +261,365 @@ func PostPageCategories(c *gin.Context) {
 	c.Status(resp.StatusCode)
 	io.Copy(c.Writer, resp.Body)
 }
+
+// PostRevertRevision sends a revert through the moderation service, which
+// holds it for review in the same cases as any other edit.
+func PostRevertRevision(c *gin.Context) {
+	modURL := fmt.Sprintf("%s/revisions/revert", config.ModerationServiceURL)
+
+	// get data from request
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	// new request to moderation service
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("page_id", c.Param("id"))
+	writer.WriteField("revision", c.Param("rev"))
+	writer.WriteField("author", c.PostForm("author"))
+	roles, accountCreated := middleware.Editor(c)
+	writer.WriteField("roles", roles)
+	writer.WriteField("account_created", accountCreated)
+
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, modURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "moderation service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostRestorePage(c *gin.Context) {
+	id := c.Param("id")
+	wikiURL := fmt.Sprintf("%s/pages/%s/restore", config.WikiServiceURL, id)
+
+	// get data from request
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	// new request to wiki service
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("slug", c.PostForm("slug"))
+	writer.WriteField("user", c.GetString("email"))
+
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	// put the page back in search results; the restore itself already succeeded
+	if resp.StatusCode == http.StatusOK {
+		if err := search.IndexPage(id); err != nil {
+			log.Printf("Warning: couldn't reindex restored page %s: %s\n", id, err)
+		}
+	}
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostPageAlias(c *gin.Context) {
+	id := c.Param("id")
+	wikiURL := fmt.Sprintf("%s/pages/%s/redirects", config.WikiServiceURL, id)
+
+	// get data from request
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	// new request to wiki service, recording the moderator from the token
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("slug", c.PostForm("slug"))
+	writer.WriteField("user", c.GetString("email"))
+
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostPageProtection(c *gin.Context) {
+	id := c.Param("id")
+	wikiURL := fmt.Sprintf("%s/pages/%s/protection", config.WikiServiceURL, id)
+
+	// get data from request
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	// new request to wiki service, recording the moderator from the token
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("level", c.PostForm("level"))
+	writer.WriteField("min_account_days", c.PostForm("min_account_days"))
+	writer.WriteField("expires_at", c.PostForm("expires_at"))
+	writer.WriteField("reason", c.PostForm("reason"))
+	writer.WriteField("user", c.GetString("email"))
+
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostDeleteRedirect(c *gin.Context) {
+	id := c.Param("id")
+	slug := c.Param("slug")
+	wikiURL := fmt.Sprintf("%s/pages/%s/redirects/%s/delete", config.WikiServiceURL, id, slug)
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostPageDraft(c *gin.Context) {
+	id := c.Param("id")
+	wikiURL := fmt.Sprintf("%s/pages/%s/draft", config.WikiServiceURL, id)
+
+	// get data from request
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+
+	// new request to wiki service, on behalf of the user from the token
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	writer.WriteField("base_revision", c.PostForm("base_revision"))
+	writer.WriteField("content", c.PostForm("content"))
+
+	if err := writer.Close(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to finalize multipart"})
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, &body)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("X-User-Email", c.GetString("email"))
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostDeletePageDraft(c *gin.Context) {
+	id := c.Param("id")
+	wikiURL := fmt.Sprintf("%s/pages/%s/draft/delete", config.WikiServiceURL, id)
+
+	req, err := http.NewRequest(http.MethodPost, wikiURL, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("X-User-Email", c.GetString("email"))
+
+	// get response from request
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+// postFormAsUser posts form fields to the wiki service on behalf of the
+// authenticated user.
+func postFormAsUser(c *gin.Context, wikiURL string, form url.Values) {
+	req, err := http.NewRequest(http.MethodPost, wikiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-User-Email", c.GetString("email"))
+	req.Header.Set("X-User-ID", middleware.UserID(c))
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for k, vals := range resp.Header {
+		for _, v := range vals {
+			c.Writer.Header().Add(k, v)
+		}
+	}
+	io.Copy(c.Writer, resp.Body)
+}
+
+func PostWatch(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+	form := url.Values{}
+	form.Set("page", c.PostForm("page"))
+	form.Set("category", c.PostForm("category"))
+	postFormAsUser(c, fmt.Sprintf("%s/watches", config.WikiServiceURL), form)
+}
+
+func PostDeleteWatch(c *gin.Context) {
+	postFormAsUser(c, fmt.Sprintf("%s/watches/%s/delete", config.WikiServiceURL, c.Param("id")), url.Values{})
+}
+
+func PostNotificationsRead(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to parse form"})
+		return
+	}
+	form := url.Values{"id": c.PostFormArray("id")}
+	postFormAsUser(c, fmt.Sprintf("%s/notifications/read", config.WikiServiceURL), form)
+}
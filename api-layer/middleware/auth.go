@@ -17,6 +17,8 @@ type Claims struct {
 	UserID uuid.UUID `json:"sub"`
 	Email  string    `json:"email"`
 	Roles  []string  `json:"roles"`
+	// AccountCreated is missing from tokens issued before it was added
+	AccountCreated *jwt.NumericDate `json:"account_created,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -50,11 +52,24 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("roles", claims.Roles)
+		if claims.AccountCreated != nil {
+			c.Set("accountCreated", claims.AccountCreated.Time)
+		}
 
 		c.Next()
 	}
 }
 
+// UserID returns the ID of the user AuthMiddleware authenticated, or "" if
+// there's none.
+func UserID(c *gin.Context) string {
+	id, ok := c.Get("userID")
+	if !ok {
+		return ""
+	}
+	return id.(uuid.UUID).String()
+}
+
 // RequireRole middleware checks if user has a specific role
 func RequireRole(role string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"api-layer/config"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Protection is a page's protection as the wiki service returns it
+type Protection struct {
+	Level          string     `json:"level"`
+	MinAccountDays int        `json:"min_account_days"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// Editor returns the roles (comma-separated) and account creation time
+// (RFC 3339, empty if the token doesn't have it) of the user making an
+// edit, for the services that check page protection.
+func Editor(c *gin.Context) (string, string) {
+	roles := strings.Join(c.GetStringSlice("roles"), ",")
+	var accountCreated string
+	if created, ok := c.Get("accountCreated"); ok {
+		accountCreated = created.(time.Time).Format(time.RFC3339)
+	}
+	return roles, accountCreated
+}
+
+// RequireEditable checks the protection of the page :id against the user's
+// roles and account age before an edit. The wiki service checks again
+// when the edit is made.
+func RequireEditable() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := http.Get(fmt.Sprintf("%s/pages/%s/protection", config.WikiServiceURL, url.PathEscape(c.Param("id"))))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "wiki service unreachable", "detail": err.Error()})
+			return
+		}
+		defer res.Body.Close()
+
+		// a missing page is left for the handler to report
+		if res.StatusCode != http.StatusOK {
+			c.Next()
+			return
+		}
+		var protection Protection
+		if err := json.NewDecoder(res.Body).Decode(&protection); err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to read page protection"})
+			return
+		}
+
+		if protection.ExpiresAt != nil && !protection.ExpiresAt.After(time.Now()) {
+			c.Next()
+			return
+		}
+		roles := c.GetStringSlice("roles")
+		moderator := slices.Contains(roles, "moderator") || slices.Contains(roles, "admin")
+		switch protection.Level {
+		case "locked":
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "this page is locked"})
+			return
+		case "moderator":
+			if !moderator {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "only moderators can edit this page"})
+				return
+			}
+		case "semi":
+			created, ok := c.Get("accountCreated")
+			minAge := time.Duration(protection.MinAccountDays) * 24 * time.Hour
+			if !moderator && (!ok || time.Since(created.(time.Time)) < minAge) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": fmt.Sprintf("only accounts at least %d days old can edit this page", protection.MinAccountDays),
+				})
+				return
+			}
+		}
+		c.Next()
+	}
+}
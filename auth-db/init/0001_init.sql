@@ -20,7 +20,7 @@ CREATE TABLE IF NOT EXISTS user_roles (
 );
 
 -- Seed roles
-INSERT INTO roles (name) VALUES ('reader'), ('contributor'), ('moderator'), ('admin')
+INSERT INTO roles (name) VALUES ('reader'), ('contributor'), ('trusted'), ('moderator'), ('admin')
 ON CONFLICT (name) DO NOTHING;
 
 -- Create indexes
@@ -0,0 +1,6 @@
+-- Migration: Add trusted role to the roles table
+-- Edits by users without a trusted role are held for review by the
+-- moderation service. Moderators and admins are trusted too.
+
+INSERT INTO roles (name) VALUES ('trusted')
+ON CONFLICT (name) DO NOTHING;
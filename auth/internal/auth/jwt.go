@@ -13,6 +13,9 @@ type Claims struct {
 	UserID uuid.UUID `json:"sub"`
 	Email  string    `json:"email"`
 	Roles  []string  `json:"roles"`
+	// AccountCreated is when the user registered, for rules based on
+	// account age like semi-protected pages
+	AccountCreated *jwt.NumericDate `json:"account_created,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -39,12 +42,13 @@ func NewJWTService(secret string, expHours int) *JWTService {
 }
 
 // GenerateToken generates a JWT token for a user
-func (j *JWTService) GenerateToken(userID uuid.UUID, email string, roles []string) (string, error) {
+func (j *JWTService) GenerateToken(userID uuid.UUID, email string, roles []string, accountCreated time.Time) (string, error) {
 	now := time.Now()
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Roles:  roles,
+		UserID:         userID,
+		Email:          email,
+		Roles:          roles,
+		AccountCreated: jwt.NewNumericDate(accountCreated),
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    j.issuer,
 			Audience:  jwt.ClaimStrings{j.audience},
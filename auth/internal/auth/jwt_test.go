@@ -14,8 +14,9 @@ func TestGenerateToken(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 	roles := []string{"reader", "contributor", "moderator"}
+	accountCreated := time.Date(2025, 8, 20, 9, 0, 0, 0, time.UTC)
 
-	token, err := jwtService.GenerateToken(userID, email, roles)
+	token, err := jwtService.GenerateToken(userID, email, roles, accountCreated)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -32,8 +33,9 @@ func TestValidateToken(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 	roles := []string{"reader", "contributor", "moderator"}
+	accountCreated := time.Date(2025, 8, 20, 9, 0, 0, 0, time.UTC)
 
-	token, err := jwtService.GenerateToken(userID, email, roles)
+	token, err := jwtService.GenerateToken(userID, email, roles, accountCreated)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -55,6 +57,10 @@ func TestValidateToken(t *testing.T) {
 		t.Errorf("Expected %d roles, got %d", len(roles), len(claims.Roles))
 	}
 
+	if claims.AccountCreated == nil || !claims.AccountCreated.Time.Equal(accountCreated) {
+		t.Errorf("Expected account created %v, got %v", accountCreated, claims.AccountCreated)
+	}
+
 	if claims.Issuer != "trevecca-pedia-auth" {
 		t.Errorf("Expected issuer 'trevecca-pedia-auth', got %v", claims.Issuer)
 	}
@@ -71,8 +77,9 @@ func TestValidateTokenInvalidSecret(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 	roles := []string{"reader"}
+	accountCreated := time.Now()
 
-	token, err := jwtService.GenerateToken(userID, email, roles)
+	token, err := jwtService.GenerateToken(userID, email, roles, accountCreated)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
@@ -98,8 +105,9 @@ func TestValidateTokenExpired(t *testing.T) {
 	userID := uuid.New()
 	email := "test@example.com"
 	roles := []string{"reader"}
+	accountCreated := time.Now()
 
-	token, err := jwtService.GenerateToken(userID, email, roles)
+	token, err := jwtService.GenerateToken(userID, email, roles, accountCreated)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
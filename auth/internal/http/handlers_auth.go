@@ -77,7 +77,7 @@ func (h *AuthHandlers) Login(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, roles)
+	token, err := h.jwtService.GenerateToken(user.ID, user.Email, roles, user.CreatedAt)
 	if err != nil {
 		log.Printf("Error generating token for user %s: %v", user.Email, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -153,7 +153,7 @@ func (h *AuthHandlers) Register(c *gin.Context) {
 	}
 
 	// Generate JWT token so user is logged in after registration
-	token, err := h.jwtService.GenerateToken(user.ID, user.Email, roles)
+	token, err := h.jwtService.GenerateToken(user.ID, user.Email, roles, user.CreatedAt)
 	if err != nil {
 		log.Printf("Error generating token for user %s: %v", user.Email, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -0,0 +1,36 @@
+-- Edits held for review by the moderation service. An approved edit is
+-- applied to the wiki as a revision by its original author; the row stays
+-- here as a record of who reviewed it.
+CREATE TABLE IF NOT EXISTS pending_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id UUID NOT NULL,
+    slug TEXT NOT NULL,
+    name TEXT NOT NULL,
+    archive_date DATE,
+    author TEXT NOT NULL,
+    base_revision UUID,
+    summary TEXT NOT NULL DEFAULT '',
+    minor BOOLEAN NOT NULL DEFAULT false,
+    -- set for a held revert: the revision the page goes back to on approval
+    reverted_from UUID,
+    content TEXT NOT NULL,
+    hold_reason TEXT NOT NULL CHECK (hold_reason IN ('untrusted_author', 'sensitive_page')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    submitted_at TIMESTAMP DEFAULT now() NOT NULL,
+    reviewed_by TEXT,
+    reviewed_at TIMESTAMP,
+    reject_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pending_revisions_status ON pending_revisions(status, submitted_at);
+CREATE INDEX IF NOT EXISTS idx_pending_revisions_page ON pending_revisions(page_id);
+
+-- Pages whose edits are held for review whoever makes them, unless they're
+-- a moderator.
+CREATE TABLE IF NOT EXISTS sensitive_pages (
+    page_id UUID PRIMARY KEY,
+    slug TEXT NOT NULL,
+    flagged_by TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    flagged_at TIMESTAMP DEFAULT now() NOT NULL
+);
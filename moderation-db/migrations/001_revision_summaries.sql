@@ -0,0 +1,11 @@
+-- Migration: Edit summaries and minor edits on held edits
+-- Held edits keep the author's summary and minor flag so they go to the
+-- wiki with them when approved.
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+ALTER TABLE pending_revisions ADD COLUMN IF NOT EXISTS summary TEXT NOT NULL DEFAULT '';
+ALTER TABLE pending_revisions ADD COLUMN IF NOT EXISTS minor BOOLEAN NOT NULL DEFAULT false;
+
+COMMIT;
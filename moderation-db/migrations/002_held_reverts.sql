@@ -0,0 +1,11 @@
+-- Migration: Held reverts
+-- A held revert keeps the revision it goes back to, so approving it reverts
+-- the page on the wiki (bringing back that revision's slug, name and archive
+-- date too) instead of applying its content as a plain edit.
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+ALTER TABLE pending_revisions ADD COLUMN IF NOT EXISTS reverted_from UUID;
+
+COMMIT;
@@ -1,7 +1,12 @@
 package main
 
 import (
+	"log"
+	"net/http"
+
 	"moderation/config"
+	"moderation/database"
+	"moderation/handlers"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,6 +16,31 @@ func main() {
 	r.SetTrustedProxies(nil)
 	gin.SetMode(gin.DebugMode)
 
+	db, err := database.Open()
+	if err != nil {
+		log.Fatalf("Couldn't connect to the moderation database: %s\n", err)
+	}
+	defer db.Close()
+	handlers.SetDatabase(db)
+
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+
+	// Edits come in here from the API layer instead of going to the wiki
+	r.POST("/revisions", handlers.SubmitRevisionHandler)
+	r.POST("/revisions/revert", handlers.RevertRevisionHandler)
+
+	r.GET("/pending", handlers.PendingListHandler)
+	r.GET("/pending/:id", handlers.PendingHandler)
+	r.GET("/pending/:id/diff", handlers.PendingDiffHandler)
+	r.POST("/pending/:id/approve", handlers.ApprovePendingHandler)
+	r.POST("/pending/:id/reject", handlers.RejectPendingHandler)
+
+	r.GET("/sensitive-pages", handlers.SensitivePagesHandler)
+	r.POST("/sensitive-pages", handlers.FlagSensitiveHandler)
+	r.POST("/sensitive-pages/:id/delete", handlers.UnflagSensitiveHandler)
+
 	port := config.GetEnv("MOD_SERVICE_PORT", "6633")
 	r.Run(":" + port)
 }
@@ -1,17 +1,27 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+var WikiServiceURL string
+
+// TrustedRoles are the roles whose edits go straight to the wiki. Edits by
+// anyone else are held for review.
+var TrustedRoles []string
+
 func init() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using defaults")
 	}
 
+	WikiServiceURL = GetEnv("WIKI_SERVICE_URL", "http://127.0.0.1:9454")
+	TrustedRoles = strings.Split(GetEnv("TRUSTED_ROLES", "trusted,moderator,admin"), ",")
 }
 
 func GetEnv(key, fallback string) string {
@@ -20,3 +30,17 @@ func GetEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// DatabaseURL returns DATABASE_URL if it's set (as on fly.io), or builds a
+// connection string from the MOD_DB_* variables.
+func DatabaseURL() string {
+	if url := GetEnv("DATABASE_URL", ""); url != "" {
+		return url
+	}
+	return fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=disable",
+		GetEnv("MOD_DB_HOST", "localhost"),
+		GetEnv("MOD_DB_PORT", "5434"),
+		GetEnv("MOD_DB_NAME", "moderation"),
+		GetEnv("MOD_DB_USER", "mod_user"),
+		GetEnv("MOD_DB_PASSWORD", "change_me_in_production"))
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"moderation/config"
+
+	_ "github.com/lib/pq"
+)
+
+// Open connects to the moderation database and checks it's reachable.
+func Open() (*sql.DB, error) {
+	db, err := sql.Open("postgres", config.DatabaseURL())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Why an edit was held for review.
+const (
+	HoldUntrustedAuthor = "untrusted_author"
+	HoldSensitivePage   = "sensitive_page"
+)
+
+// Review status of a held edit.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// PendingRevision is an edit held for review instead of being applied to
+// the wiki. Content is left out of lists. A held revert has RevertedFrom
+// set, and the content of that revision.
+type PendingRevision struct {
+	ID           uuid.UUID  `json:"id"`
+	PageID       uuid.UUID  `json:"page_id"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	ArchiveDate  *time.Time `json:"archive_date"`
+	Author       string     `json:"author"`
+	BaseRevision *uuid.UUID `json:"base_revision"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
+	RevertedFrom *uuid.UUID `json:"reverted_from"`
+	Content      string     `json:"content,omitempty"`
+	HoldReason   string     `json:"hold_reason"`
+	Status       string     `json:"status"`
+	SubmittedAt  time.Time  `json:"submitted_at"`
+	ReviewedBy   *string    `json:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at"`
+	RejectReason *string    `json:"reject_reason"`
+}
+
+// SensitivePage is a page whose edits are held for review unless a
+// moderator makes them.
+type SensitivePage struct {
+	PageID    uuid.UUID `json:"page_id"`
+	Slug      string    `json:"slug"`
+	FlaggedBy string    `json:"flagged_by"`
+	Note      string    `json:"note"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
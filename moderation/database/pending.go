@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrAlreadyReviewed is returned when approving or rejecting an edit that's
+// no longer pending.
+var ErrAlreadyReviewed = errors.New("this edit has already been reviewed")
+
+const pendingColumns = `id, page_id, slug, name, archive_date, author, base_revision, summary, minor,
+	reverted_from, hold_reason, status, submitted_at, reviewed_by, reviewed_at, reject_reason`
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPending(row scanner, p *PendingRevision, extra ...any) error {
+	dest := []any{&p.ID, &p.PageID, &p.Slug, &p.Name, &p.ArchiveDate, &p.Author, &p.BaseRevision, &p.Summary, &p.Minor,
+		&p.RevertedFrom, &p.HoldReason, &p.Status, &p.SubmittedAt, &p.ReviewedBy, &p.ReviewedAt, &p.RejectReason}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// CreatePending holds an edit for review, filling in its ID, status and
+// submission time.
+func CreatePending(ctx context.Context, db *sql.DB, p *PendingRevision) error {
+	return db.QueryRowContext(ctx, `
+		INSERT INTO pending_revisions (page_id, slug, name, archive_date, author, base_revision, summary, minor, content, reverted_from, hold_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, status, submitted_at;
+	`, p.PageID, p.Slug, p.Name, p.ArchiveDate, p.Author, p.BaseRevision, p.Summary, p.Minor, p.Content, p.RevertedFrom,
+		p.HoldReason).
+		Scan(&p.ID, &p.Status, &p.SubmittedAt)
+}
+
+// GetPending returns a held edit with its content.
+func GetPending(ctx context.Context, db *sql.DB, id uuid.UUID) (*PendingRevision, error) {
+	var p PendingRevision
+	err := scanPending(db.QueryRowContext(ctx, `
+		SELECT `+pendingColumns+`, content FROM pending_revisions WHERE id=$1;
+	`, id), &p, &p.Content)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPending lists held edits with a status, oldest first, optionally only
+// the ones by an author.
+func ListPending(ctx context.Context, db *sql.DB, status string, author string, index int, count int) ([]PendingRevision, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+pendingColumns+` FROM pending_revisions
+		WHERE status=$1 AND ($2 = '' OR author=$2)
+		ORDER BY submitted_at
+		LIMIT $3 OFFSET $4;
+	`, status, author, count, index)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []PendingRevision{}
+	for rows.Next() {
+		var p PendingRevision
+		if err := scanPending(rows, &p); err != nil {
+			return nil, err
+		}
+		items = append(items, p)
+	}
+	return items, rows.Err()
+}
+
+// LockPending locks a pending edit for review until tx ends. It returns
+// ErrAlreadyReviewed if someone else got to it first.
+func LockPending(ctx context.Context, tx *sql.Tx, id uuid.UUID) (*PendingRevision, error) {
+	var p PendingRevision
+	err := scanPending(tx.QueryRowContext(ctx, `
+		SELECT `+pendingColumns+`, content FROM pending_revisions WHERE id=$1 FOR UPDATE;
+	`, id), &p, &p.Content)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusPending {
+		return nil, ErrAlreadyReviewed
+	}
+	return &p, nil
+}
+
+// MarkReviewed records a moderator's decision on a locked pending edit.
+// reason is only kept for rejections.
+func MarkReviewed(ctx context.Context, tx *sql.Tx, id uuid.UUID, status string, reviewer string, reason string) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE pending_revisions
+		SET status=$2, reviewed_by=$3, reviewed_at=now(), reject_reason=NULLIF($4, '')
+		WHERE id=$1;
+	`, id, status, reviewer, reason)
+	return err
+}
+
+// ReleasePending puts an edit claimed for approval back in the queue, for
+// when the wiki refuses it.
+func ReleasePending(ctx context.Context, db *sql.DB, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `
+		UPDATE pending_revisions
+		SET status='pending', reviewed_by=NULL, reviewed_at=NULL
+		WHERE id=$1 AND status='approved';
+	`, id)
+	return err
+}
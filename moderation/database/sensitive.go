@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// IsSensitive reports whether a page has been flagged as sensitive.
+func IsSensitive(ctx context.Context, db *sql.DB, pageId uuid.UUID) (bool, error) {
+	var sensitive bool
+	err := db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM sensitive_pages WHERE page_id=$1);
+	`, pageId).Scan(&sensitive)
+	return sensitive, err
+}
+
+// GetSensitivePages lists the flagged pages, most recently flagged first.
+func GetSensitivePages(ctx context.Context, db *sql.DB) ([]SensitivePage, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT page_id, slug, flagged_by, note, flagged_at
+		FROM sensitive_pages
+		ORDER BY flagged_at DESC;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := []SensitivePage{}
+	for rows.Next() {
+		var p SensitivePage
+		if err := rows.Scan(&p.PageID, &p.Slug, &p.FlaggedBy, &p.Note, &p.FlaggedAt); err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+// FlagSensitive flags a page as sensitive, or updates the note of one
+// that's already flagged.
+func FlagSensitive(ctx context.Context, db *sql.DB, p *SensitivePage) error {
+	return db.QueryRowContext(ctx, `
+		INSERT INTO sensitive_pages (page_id, slug, flagged_by, note)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (page_id) DO UPDATE
+		SET slug=EXCLUDED.slug, flagged_by=EXCLUDED.flagged_by, note=EXCLUDED.note
+		RETURNING flagged_at;
+	`, p.PageID, p.Slug, p.FlaggedBy, p.Note).Scan(&p.FlaggedAt)
+}
+
+// UnflagSensitive removes a page's flag, and reports whether it had one.
+func UnflagSensitive(ctx context.Context, db *sql.DB, pageId uuid.UUID) (bool, error) {
+	res, err := db.ExecContext(ctx, `
+		DELETE FROM sensitive_pages WHERE page_id=$1;
+	`, pageId)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -3,8 +3,11 @@ module moderation
 go 1.25.5
 
 require (
+	github.com/aymanbagabas/go-udiff v0.3.1
 	github.com/gin-gonic/gin v1.11.0
+	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
+	github.com/lib/pq v1.10.9
 )
 
 require (
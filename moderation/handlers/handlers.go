@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+
+	"moderation/database"
+	"moderation/wiki"
+
+	"github.com/gin-gonic/gin"
+)
+
+var db *sql.DB
+
+func SetDatabase(d *sql.DB) {
+	db = d
+}
+
+// moderatorRoles can edit sensitive pages without review.
+var moderatorRoles = []string{"moderator", "admin"}
+
+// MaxReasonLength is the longest a rejection reason or a sensitive page's
+// note can be, in characters.
+const MaxReasonLength = 500
+
+// MaxSummaryLength is the wiki's limit on edit summaries, checked here too
+// so a held edit isn't refused when it's approved.
+const MaxSummaryLength = 500
+
+func hasAnyRole(roles []string, wanted []string) bool {
+	for _, role := range roles {
+		if slices.Contains(wanted, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// abortWithError responds to a failed request. Responses from the wiki are
+// passed on as they are, so its errors (a merge conflict, a deleted page)
+// reach the client unchanged.
+func abortWithError(c *gin.Context, err error) {
+	var wikiErr *wiki.ResponseError
+	switch {
+	case errors.As(err, &wikiErr):
+		c.Data(wikiErr.StatusCode, wikiErr.ContentType, wikiErr.Body)
+		c.Abort()
+	case errors.Is(err, sql.ErrNoRows):
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "pending edit not found"})
+	case errors.Is(err, database.ErrAlreadyReviewed):
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		log.Printf("Error: %s\n", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	}
+}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"moderation/database"
+	"moderation/wiki"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func PendingListHandler(c *gin.Context) {
+	status := c.DefaultQuery("status", database.StatusPending)
+	switch status {
+	case database.StatusPending, database.StatusApproved, database.StatusRejected:
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+		return
+	}
+	ind, err := strconv.Atoi(c.DefaultQuery("index", "0"))
+	if err != nil {
+		ind = 0
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil {
+		count = 10
+	}
+
+	items, err := database.ListPending(context.Background(), db, status, c.Query("author"), ind, count)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// pendingParam parses the :id of a held edit, responding with a 400 if it
+// isn't one.
+func pendingParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.UUID{}, false
+	}
+	return id, true
+}
+
+func PendingHandler(c *gin.Context) {
+	id, ok := pendingParam(c)
+	if !ok {
+		return
+	}
+	item, err := database.GetPending(context.Background(), db, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// PendingDiffHandler shows what a held edit changes, as a unified diff
+// against the revision it was based on. stale means the page has been
+// edited since, and approving will merge the edit with those changes.
+func PendingDiffHandler(c *gin.Context) {
+	id, ok := pendingParam(c)
+	if !ok {
+		return
+	}
+	item, err := database.GetPending(context.Background(), db, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	page, err := wiki.GetPage(item.PageID.String())
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	var base string
+	if item.BaseRevision != nil {
+		base, err = wiki.GetRevisionContent(item.PageID, *item.BaseRevision)
+		if err != nil {
+			abortWithError(c, err)
+			return
+		}
+	}
+
+	filename := item.Slug + ".md"
+	stale := page.LastEdit != nil && (item.BaseRevision == nil || *page.LastEdit != *item.BaseRevision)
+	c.JSON(http.StatusOK, gin.H{
+		"id":               item.ID,
+		"page_id":          item.PageID,
+		"base_revision":    item.BaseRevision,
+		"current_revision": page.LastEdit,
+		"stale":            stale,
+		"diff":             udiff.Unified(filename, filename, base, item.Content),
+	})
+}
+
+// ApprovePendingHandler applies a held edit to the wiki as a revision by its
+// author. If the wiki refuses it, e.g. with a 409 because it conflicts with
+// later edits, its response is returned and the edit goes back to pending.
+// Page protection is checked against the reviewer's roles, sent as roles
+// and account_created.
+func ApprovePendingHandler(c *gin.Context) {
+	ctx := context.Background()
+	id, ok := pendingParam(c)
+	if !ok {
+		return
+	}
+	reviewer := c.PostForm("reviewer")
+	if reviewer == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "reviewer is required"})
+		return
+	}
+
+	// claim the edit as approved before the wiki applies it, so two
+	// moderators can't approve it twice and an edit the wiki has made is
+	// never left pending
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	item, err := database.LockPending(ctx, tx, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if err := database.MarkReviewed(ctx, tx, id, database.StatusApproved, reviewer, ""); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	err = applyPending(item, c.PostForm("roles"), c.PostForm("account_created"))
+	if err != nil {
+		// the wiki didn't make the edit, so it goes back in the queue
+		if releaseErr := database.ReleasePending(ctx, db, id); releaseErr != nil {
+			log.Printf("Error: releasing %s: %s\n", id, releaseErr)
+		}
+		abortWithError(c, err)
+		return
+	}
+
+	item, err = database.GetPending(ctx, db, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	item.Content = ""
+	c.JSON(http.StatusOK, item)
+}
+
+// applyPending makes a held edit on the wiki as its author, checking page
+// protection against roles and accountCreated. Held reverts go through the
+// wiki's revert, which brings back the old revision's slug, name and
+// archive date and refuses the same reverts it always would.
+func applyPending(item *database.PendingRevision, roles string, accountCreated string) error {
+	if item.RevertedFrom != nil {
+		return wiki.PostRevert(item.PageID, *item.RevertedFrom, item.Author, roles, accountCreated)
+	}
+	return wiki.PostRevision(wiki.Revision{
+		PageID:       item.PageID,
+		Author:       item.Author,
+		Slug:         item.Slug,
+		Name:         item.Name,
+		ArchiveDate:  item.ArchiveDate,
+		BaseRevision: item.BaseRevision,
+		Summary:      item.Summary,
+		Minor:        item.Minor,
+		Content:      item.Content,
+
+		Roles:          roles,
+		AccountCreated: accountCreated,
+	})
+}
+
+func RejectPendingHandler(c *gin.Context) {
+	ctx := context.Background()
+	id, ok := pendingParam(c)
+	if !ok {
+		return
+	}
+	reviewer := c.PostForm("reviewer")
+	if reviewer == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "reviewer is required"})
+		return
+	}
+	reason := c.PostForm("reason")
+	if reason == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+	if utf8.RuneCountInString(reason) > MaxReasonLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "reason is too long"})
+		return
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := database.LockPending(ctx, tx, id); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if err := database.MarkReviewed(ctx, tx, id, database.StatusRejected, reviewer, reason); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	item, err := database.GetPending(ctx, db, id)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	item.Content = ""
+	c.JSON(http.StatusOK, item)
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"moderation/config"
+	"moderation/database"
+	"moderation/wiki"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// holdReason decides whether an edit by a user with roles needs review, and
+// returns why, or "" if it can go straight to the wiki.
+func holdReason(roles []string, sensitive bool) string {
+	if sensitive && !hasAnyRole(roles, moderatorRoles) {
+		return database.HoldSensitivePage
+	}
+	if !hasAnyRole(roles, config.TrustedRoles) {
+		return database.HoldUntrustedAuthor
+	}
+	return ""
+}
+
+// protectionRefusal is why a page's protection keeps an editor with roles,
+// whose account was made at accountCreated (RFC 3339), from editing it, or
+// "" if it doesn't.
+func protectionRefusal(p *wiki.Protection, roles []string, accountCreated string, now time.Time) string {
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(now) {
+		return ""
+	}
+	switch p.Level {
+	case "locked":
+		return "this page is locked"
+	case "moderator":
+		if !hasAnyRole(roles, moderatorRoles) {
+			return "only moderators can edit this page"
+		}
+	case "semi":
+		if hasAnyRole(roles, moderatorRoles) {
+			return ""
+		}
+		created, err := time.Parse(time.RFC3339, accountCreated)
+		minAge := time.Duration(p.MinAccountDays) * 24 * time.Hour
+		if err != nil || now.Sub(created) < minAge {
+			return fmt.Sprintf("only accounts at least %d days old can edit this page", p.MinAccountDays)
+		}
+	}
+	return ""
+}
+
+// authorCanEdit checks a page's protection against the author of an edit
+// about to be held, responding with a 403 if they couldn't make it. The
+// wiki only checks when a held edit is approved, against the moderator.
+func authorCanEdit(c *gin.Context, pageId uuid.UUID, roles []string) bool {
+	protection, err := wiki.GetProtection(pageId)
+	if err != nil {
+		abortWithError(c, err)
+		return false
+	}
+	if refusal := protectionRefusal(protection, roles, c.PostForm("account_created"), time.Now()); refusal != "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": refusal})
+		return false
+	}
+	return true
+}
+
+// SubmitRevisionHandler takes an edit in the same form as the wiki's
+// POST /pages/:id/revisions, plus the editor's comma-separated roles. Edits
+// that don't need review are passed on to the wiki and its response is
+// returned. Others are held, with a 202 and the held edit.
+func SubmitRevisionHandler(c *gin.Context) {
+	ctx := context.Background()
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "bad request format"})
+		return
+	}
+	fileHeader, err := c.FormFile("new_content")
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "new_content file is required"})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	pageId := c.PostForm("page_id")
+	if pageId == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "page_id is required"})
+		return
+	}
+	page, err := wiki.GetPage(pageId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	rev := wiki.Revision{
+		PageID:  page.UUID,
+		Author:  c.PostForm("author"),
+		Slug:    c.PostForm("slug"),
+		Name:    c.PostForm("name"),
+		Summary: strings.Join(strings.Fields(c.PostForm("summary")), " "),
+		Minor:   c.PostForm("minor") == "true",
+		Content: string(content),
+
+		Roles:          c.PostForm("roles"),
+		AccountCreated: c.PostForm("account_created"),
+	}
+	if utf8.RuneCountInString(rev.Summary) > MaxSummaryLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "summary is too long"})
+		return
+	}
+	if rev.Slug == "" {
+		rev.Slug = page.Slug
+	}
+	if rev.Name == "" {
+		rev.Name = page.Name
+	}
+	if s := c.PostForm("archive_date"); s != "" {
+		archiveDate, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "bad request format"})
+			return
+		}
+		rev.ArchiveDate = &archiveDate
+	}
+	if s := c.PostForm("base_revision"); s != "" {
+		baseRev, err := uuid.Parse(s)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid base_revision"})
+			return
+		}
+		rev.BaseRevision = &baseRev
+	} else {
+		// a held edit is applied later, so it needs a base for the wiki to
+		// merge it with what's changed since
+		rev.BaseRevision = page.LastEdit
+	}
+
+	sensitive, err := database.IsSensitive(ctx, db, page.UUID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	roles := strings.Split(c.PostForm("roles"), ",")
+	reason := holdReason(roles, sensitive)
+	if reason == "" {
+		if err := wiki.PostRevision(rev); err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+		return
+	}
+	if !authorCanEdit(c, page.UUID, roles) {
+		return
+	}
+
+	pending := database.PendingRevision{
+		PageID:       rev.PageID,
+		Slug:         rev.Slug,
+		Name:         rev.Name,
+		ArchiveDate:  rev.ArchiveDate,
+		Author:       rev.Author,
+		BaseRevision: rev.BaseRevision,
+		Summary:      rev.Summary,
+		Minor:        rev.Minor,
+		Content:      rev.Content,
+		HoldReason:   reason,
+	}
+	if err := database.CreatePending(ctx, db, &pending); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	pending.Content = ""
+	c.JSON(http.StatusAccepted, pending)
+}
+
+// revertSummary is the summary the wiki gives a revert, used for held ones
+// too so they read the same once approved.
+func revertSummary(author string, dateTime time.Time) string {
+	return fmt.Sprintf("Reverted to the revision by %s from %s", author, dateTime.Format("2006-01-02 15:04"))
+}
+
+// RevertRevisionHandler takes a revert of page_id to revision, with the
+// author, roles and account_created as for SubmitRevisionHandler. Reverts
+// that don't need review are passed on to the wiki's revert. Others are
+// held, with a 202 and the held edit, and reverted on the wiki when they're
+// approved.
+func RevertRevisionHandler(c *gin.Context) {
+	ctx := context.Background()
+	pageId := c.PostForm("page_id")
+	if pageId == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "page_id is required"})
+		return
+	}
+	revId, err := uuid.Parse(c.PostForm("revision"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid revision"})
+		return
+	}
+	page, err := wiki.GetPage(pageId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	author := c.PostForm("author")
+	roles := c.PostForm("roles")
+
+	sensitive, err := database.IsSensitive(ctx, db, page.UUID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	reason := holdReason(strings.Split(roles, ","), sensitive)
+	if reason == "" {
+		if err := wiki.PostRevert(page.UUID, revId, author, roles, c.PostForm("account_created")); err != nil {
+			abortWithError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if !authorCanEdit(c, page.UUID, strings.Split(roles, ",")) {
+		return
+	}
+
+	old, err := wiki.GetRevision(page.UUID, revId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if old.PageID != page.UUID {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+	// the wiki refuses these too, so don't hold one that can't be approved
+	if old.DeletedAt != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "revision is deleted"})
+		return
+	}
+
+	// approving it reverts the page on the wiki; the old revision's content
+	// is kept so moderators can see what the revert changes
+	pending := database.PendingRevision{
+		PageID:       page.UUID,
+		Slug:         old.Slug,
+		Name:         old.Name,
+		ArchiveDate:  old.ArchiveDate,
+		Author:       author,
+		BaseRevision: page.LastEdit,
+		Summary:      revertSummary(old.Author, old.DateTime),
+		Content:      old.Content,
+		RevertedFrom: &old.UUID,
+		HoldReason:   reason,
+	}
+	if err := database.CreatePending(ctx, db, &pending); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	pending.Content = ""
+	c.JSON(http.StatusAccepted, pending)
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"unicode/utf8"
+
+	"moderation/database"
+	"moderation/wiki"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SensitivePagesHandler(c *gin.Context) {
+	pages, err := database.GetSensitivePages(context.Background(), db)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pages)
+}
+
+// FlagSensitiveHandler flags the page page_id (an ID or slug) as
+// sensitive, so its edits are held for review unless a moderator makes
+// them.
+func FlagSensitiveHandler(c *gin.Context) {
+	user := c.PostForm("user")
+	if user == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "user is required"})
+		return
+	}
+	note := c.PostForm("note")
+	if utf8.RuneCountInString(note) > MaxReasonLength {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "note is too long"})
+		return
+	}
+	pageId := c.PostForm("page_id")
+	if pageId == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "page_id is required"})
+		return
+	}
+	page, err := wiki.GetPage(pageId)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+
+	flagged := database.SensitivePage{
+		PageID:    page.UUID,
+		Slug:      page.Slug,
+		FlaggedBy: user,
+		Note:      note,
+	}
+	if err := database.FlagSensitive(context.Background(), db, &flagged); err != nil {
+		abortWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, flagged)
+}
+
+// UnflagSensitiveHandler takes the flag off the page :id (an ID or slug).
+func UnflagSensitiveHandler(c *gin.Context) {
+	page, err := wiki.GetPage(c.Param("id"))
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	removed, err := database.UnflagSensitive(context.Background(), db, page.UUID)
+	if err != nil {
+		abortWithError(c, err)
+		return
+	}
+	if !removed {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "page isn't flagged as sensitive"})
+		return
+	}
+	c.Status(http.StatusOK)
+}
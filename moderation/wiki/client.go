@@ -0,0 +1,211 @@
+// Package wiki talks to the wiki service, to look pages up and to apply
+// approved edits.
+package wiki
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"moderation/config"
+
+	"github.com/google/uuid"
+)
+
+// Page is the part of a wiki page the moderation service needs.
+type Page struct {
+	UUID        uuid.UUID  `json:"uuid"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	ArchiveDate *time.Time `json:"archive_date"`
+	LastEdit    *uuid.UUID `json:"last_edit"`
+}
+
+// Revision is what the service sends the wiki to make an edit.
+type Revision struct {
+	PageID       uuid.UUID
+	Author       string
+	Slug         string
+	Name         string
+	ArchiveDate  *time.Time
+	BaseRevision *uuid.UUID
+	Summary      string
+	Minor        bool
+	Content      string
+	// Roles and AccountCreated are whoever's making the edit, for the
+	// wiki's page protection check: the author, or the moderator approving
+	// a held edit.
+	Roles          string
+	AccountCreated string
+}
+
+// ResponseError is a response from the wiki other than a success, kept so
+// it can be passed on to the client as it is.
+type ResponseError struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("wiki service responded %d: %s", e.StatusCode, e.Body)
+}
+
+func get(path string, v any) error {
+	res, err := http.Get(config.WikiServiceURL + path)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return &ResponseError{res.StatusCode, res.Header.Get("Content-Type"), body}
+	}
+	return json.Unmarshal(body, v)
+}
+
+// GetPage looks a page up by its ID or slug. Deleted pages aren't found.
+func GetPage(id string) (*Page, error) {
+	var page Page
+	err := get("/pages/"+url.PathEscape(id), &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// GetRevisionContent returns a page's content at a revision.
+func GetRevisionContent(pageId uuid.UUID, revId uuid.UUID) (string, error) {
+	var rev struct {
+		Content string `json:"content"`
+	}
+	err := get(fmt.Sprintf("/pages/%s/revisions/%s", pageId, revId), &rev)
+	return rev.Content, err
+}
+
+// Protection is a page's protection level, for checking that the author of
+// an edit could make it before it's held.
+type Protection struct {
+	Level          string     `json:"level"`
+	MinAccountDays int        `json:"min_account_days"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// GetProtection returns a page's protection. Unprotected pages have the
+// level "open".
+func GetProtection(pageId uuid.UUID) (*Protection, error) {
+	var protection Protection
+	err := get(fmt.Sprintf("/pages/%s/protection", pageId), &protection)
+	if err != nil {
+		return nil, err
+	}
+	return &protection, nil
+}
+
+// PastRevision is a revision of a page, as reverting to it needs it.
+type PastRevision struct {
+	UUID        uuid.UUID  `json:"uuid"`
+	PageID      uuid.UUID  `json:"page_id"`
+	DateTime    time.Time  `json:"rev_date_time"`
+	Author      string     `json:"author"`
+	Slug        string     `json:"slug"`
+	Name        string     `json:"name"`
+	ArchiveDate *time.Time `json:"archive_date"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	Content     string     `json:"content"`
+}
+
+// GetRevision returns a revision of a page with its content.
+func GetRevision(pageId uuid.UUID, revId uuid.UUID) (*PastRevision, error) {
+	var rev PastRevision
+	err := get(fmt.Sprintf("/pages/%s/revisions/%s", pageId, revId), &rev)
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// PostRevision makes an edit. If the page changed since the edit's base
+// revision, the wiki merges the two, and a conflict comes back as a
+// ResponseError with status 409.
+func PostRevision(rev Revision) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("page_id", rev.PageID.String())
+	writer.WriteField("author", rev.Author)
+	writer.WriteField("slug", rev.Slug)
+	writer.WriteField("name", rev.Name)
+	if rev.ArchiveDate != nil {
+		writer.WriteField("archive_date", rev.ArchiveDate.Format("2006-01-02"))
+	}
+	if rev.BaseRevision != nil {
+		writer.WriteField("base_revision", rev.BaseRevision.String())
+	}
+	writer.WriteField("summary", rev.Summary)
+	writer.WriteField("minor", strconv.FormatBool(rev.Minor))
+	writer.WriteField("roles", rev.Roles)
+	writer.WriteField("account_created", rev.AccountCreated)
+	part, err := writer.CreateFormFile("new_content", rev.Slug+".md")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(part, rev.Content); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	wikiURL := fmt.Sprintf("%s/pages/%s/revisions", config.WikiServiceURL, rev.PageID)
+	res, err := http.Post(wikiURL, writer.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		resBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return &ResponseError{res.StatusCode, res.Header.Get("Content-Type"), resBody}
+	}
+	return nil
+}
+
+// PostRevert restores a page to an earlier revision as a new revision by
+// author. roles and accountCreated are for the wiki's page protection check,
+// as in Revision.
+func PostRevert(pageId uuid.UUID, revId uuid.UUID, author string, roles string, accountCreated string) error {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	writer.WriteField("author", author)
+	writer.WriteField("roles", roles)
+	writer.WriteField("account_created", accountCreated)
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	wikiURL := fmt.Sprintf("%s/pages/%s/revisions/%s/revert", config.WikiServiceURL, pageId, revId)
+	res, err := http.Post(wikiURL, writer.FormDataContentType(), &body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		resBody, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return &ResponseError{res.StatusCode, res.Header.Get("Content-Type"), resBody}
+	}
+	return nil
+}
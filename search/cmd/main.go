@@ -6,6 +6,7 @@ import (
 	"search/config"
 	"search/handlers"
 	"search/service"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -27,11 +28,31 @@ func main() {
 
 	handlers.SetSearchService(s)
 
+	if config.ReindexInterval > 0 {
+		go indexQueued(s, config.ReindexInterval)
+	}
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 	})
 	r.GET("/search", handlers.SearchHandler)
 	r.POST("/reindex", handlers.ReindexHandler)
+	r.POST("/index/:slug", handlers.IndexPageHandler)
 
 	r.Run(":7724")
 }
+
+// indexQueued keeps indexing the pages the wiki queues when a template they
+// include changes.
+func indexQueued(s *service.SearchService, interval time.Duration) {
+	for range time.Tick(interval) {
+		indexed, err := s.IndexQueued()
+		if err != nil {
+			log.Printf("Warning: Couldn't fetch the reindex queue: %s\n", err)
+			continue
+		}
+		if indexed > 0 {
+			log.Printf("Indexed %d queued pages\n", indexed)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,6 +12,14 @@ import (
 var WikiURL string
 var IndexDir string
 
+// WikiServiceURL is the wiki service itself, for its reindex queue, which
+// the API layer doesn't expose.
+var WikiServiceURL string
+
+// ReindexInterval is how often pages queued by the wiki, because a template
+// they include changed, are indexed again. Zero turns it off.
+var ReindexInterval time.Duration
+
 func init() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("Warning: .env file not found, using defaults")
@@ -18,7 +27,14 @@ func init() {
 
 	apiURL := GetEnv("API_LAYER_URL", "http://127.0.0.1:2745/v1")
 	WikiURL = fmt.Sprintf("%s/wiki", apiURL)
+	WikiServiceURL = GetEnv("WIKI_SERVICE_URL", "http://127.0.0.1:9454")
 	IndexDir = GetEnv("INDEX_DIR", "../index")
+
+	interval, err := time.ParseDuration(GetEnv("REINDEX_INTERVAL", "1m"))
+	if err != nil {
+		log.Fatalf("REINDEX_INTERVAL: %s\n", err)
+	}
+	ReindexInterval = interval
 }
 
 func GetEnv(key, fallback string) string {
@@ -50,3 +50,13 @@ func ReindexHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "reindex completed successfully"})
 }
+
+func IndexPageHandler(c *gin.Context) {
+	err := searchService.IndexPage(c.Param("slug"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, fmt.Sprintf("err: %s", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "page indexed successfully"})
+}
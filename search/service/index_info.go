@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"search/config"
 	"time"
 
@@ -42,6 +43,74 @@ func getIndexInfoBatch(index int, count int) ([]IndexInfo, error) {
 	return indexInfo, nil
 }
 
+func getIndexInfo(slug string) (*IndexInfo, error) {
+	url := fmt.Sprintf("%s/indexable-pages/%s", config.WikiURL, slug)
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: status %d", slug, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var indexInfo IndexInfo
+	err = json.Unmarshal(body, &indexInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexInfo, nil
+}
+
+// ReindexEntry is a page the wiki has queued to be indexed again.
+type ReindexEntry struct {
+	Slug     string    `json:"slug"`
+	MarkedAt time.Time `json:"marked_at"`
+}
+
+func getReindexQueue(count int) ([]ReindexEntry, error) {
+	url := fmt.Sprintf("%s/reindex-queue?count=%d", config.WikiServiceURL, count)
+	res, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching reindex queue: status %d", res.StatusCode)
+	}
+
+	var entries []ReindexEntry
+	err = json.NewDecoder(res.Body).Decode(&entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// clearReindexMark tells the wiki a queued page has been indexed.
+func clearReindexMark(entry ReindexEntry) error {
+	res, err := http.PostForm(fmt.Sprintf("%s/reindex-queue/%s/done", config.WikiServiceURL, entry.Slug),
+		url.Values{"marked_at": {entry.MarkedAt.Format(time.RFC3339Nano)}})
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("clearing %s: status %d", entry.Slug, res.StatusCode)
+	}
+	return nil
+}
+
 func buildIndexMapping() mapping.IndexMapping {
 	docMapping := bleve.NewDocumentMapping()
 
@@ -2,6 +2,7 @@ package service
 
 import (
 	"fmt"
+	"log"
 
 	"github.com/blevesearch/bleve/v2"
 )
@@ -67,6 +68,39 @@ func (s *SearchService) IndexAll() error {
 	return s.index.Batch(batch)
 }
 
+// IndexPage (re)indexes a single page, e.g. after it's been restored.
+func (s *SearchService) IndexPage(slug string) error {
+	indexInfo, err := getIndexInfo(slug)
+	if err != nil {
+		return err
+	}
+	return s.index.Index(indexInfo.Slug, indexInfo)
+}
+
+// IndexQueued indexes the pages the wiki has queued, because a template they
+// include changed, and returns how many were indexed. A page that fails
+// stays queued for next time.
+func (s *SearchService) IndexQueued() (int, error) {
+	queue, err := getReindexQueue(50)
+	if err != nil {
+		return 0, err
+	}
+
+	indexed := 0
+	for _, entry := range queue {
+		err = s.IndexPage(entry.Slug)
+		if err == nil {
+			err = clearReindexMark(entry)
+		}
+		if err != nil {
+			log.Printf("Couldn't index queued page %s: %s\n", entry.Slug, err)
+			continue
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
 func (s *SearchService) Search(queryString string, from, size int) (*bleve.SearchResult, error) {
 	nameQuery := bleve.NewMatchQuery(queryString)
 	nameQuery.SetField("name")
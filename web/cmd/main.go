@@ -23,6 +23,10 @@ func main() {
 	r.GET("/pages/:id/history", wiki.GetPageHistory)
 	r.GET("/pages/:id/history/:revId", wiki.GetPageHistory)
 	r.GET("/pages/:id/history/timeline", wiki.GetTimelinePartial)
+	r.GET("/recent-changes", wiki.GetRecentChanges)
+	r.GET("/feeds/recent-changes", wiki.GetRecentChangesFeed)
+	r.GET("/feeds/pages/:id", wiki.GetPageFeed)
+	r.GET("/feeds/categories/*path", wiki.GetCategoryFeed)
 	r.GET("/search", search.GetSearchPage)
 	r.GET("/login", auth.GetLoginPage)
 	r.GET("/users/:username", users.GetUserProfilePage)
@@ -42,7 +46,21 @@ func main() {
 		protected.POST("/pages/new", wiki.PostCreatePage)
 		protected.GET("/pages/:id/edit", wiki.GetEditPage)
 		protected.POST("/pages/:id/edit", wiki.PostEditPage)
+		protected.POST("/pages/:id/draft", wiki.PostSaveDraft)
+		protected.POST("/pages/:id/draft/delete", wiki.PostDiscardDraft)
 		protected.POST("/update-preview", wiki.PostPreview)
+		protected.POST("/watches", wiki.PostWatch)
+		protected.POST("/watches/:id/delete", wiki.PostUnwatch)
+		protected.GET("/notifications", wiki.GetNotifications)
+		protected.GET("/notifications/unread-count", wiki.GetUnreadCount)
+		protected.POST("/notifications/read", wiki.PostMarkRead)
+	}
+
+	// Contributor routes - require contributor role
+	contributor := r.Group("/")
+	contributor.Use(auth.RequireRole("contributor"))
+	{
+		contributor.POST("/pages/:id/history/:revId/revert", wiki.PostRevertRevision)
 	}
 
 	// Moderator-only routes - require moderator role
@@ -50,6 +68,14 @@ func main() {
 	moderator.Use(auth.RequireRole("moderator"))
 	{
 		moderator.POST("/pages/:id/delete", wiki.PostDeletePage)
+		moderator.GET("/deleted-pages", wiki.GetDeletedPages)
+		moderator.POST("/pages/:id/restore", wiki.PostRestorePage)
+		moderator.POST("/pages/:id/protection", wiki.PostPageProtection)
+		moderator.GET("/reports", wiki.GetReports)
+		moderator.GET("/review", wiki.GetReviewQueue)
+		moderator.GET("/review/:id", wiki.GetReviewItem)
+		moderator.POST("/review/:id/approve", wiki.PostApproveReview)
+		moderator.POST("/review/:id/reject", wiki.PostRejectReview)
 	}
 
 	r.GET("/image/*id", image.GetImage)
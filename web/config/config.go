@@ -12,6 +12,11 @@ var WikiURL string
 var SearchURL string
 var ImageServiceURL string
 var AuthURL string
+var ModerationURL string
+
+// SiteURL is the public address of the site, for the links in feeds. When
+// it isn't set, links use the host of the request.
+var SiteURL string
 
 func init() {
 	if err := godotenv.Load(); err != nil {
@@ -22,6 +27,8 @@ func init() {
 	WikiURL = fmt.Sprintf("%s/wiki", apiURL)
 	SearchURL = fmt.Sprintf("%s/search", apiURL)
 	AuthURL = fmt.Sprintf("%s/auth", apiURL)
+	ModerationURL = fmt.Sprintf("%s/moderation", apiURL)
+	SiteURL = GetEnv("SITE_URL", "")
   ImageServiceURL = GetEnv("IMAGE_SERVICE_URL", "https://treveccabuddy.tp-images.workers.dev")
 }
 
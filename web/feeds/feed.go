@@ -0,0 +1,57 @@
+// Package feeds writes Atom 1.0 and RSS 2.0 feeds.
+package feeds
+
+import (
+	"time"
+)
+
+// Formats a feed can be written in
+const (
+	Atom = "atom"
+	RSS  = "rss"
+)
+
+// Feed is a list of entries, newest first. Links are absolute.
+type Feed struct {
+	ID      string
+	Title   string
+	Link    string
+	SelfURL string
+	Entries []Entry
+}
+
+// Entry is one item of a feed. Feeds are public, so the author is a name
+// and a link to their profile, never an email address.
+type Entry struct {
+	ID         string
+	Title      string
+	Link       string
+	AuthorName string
+	AuthorURI  string
+	Updated    time.Time
+	Summary    string
+}
+
+// Updated is the time of the newest entry, or the zero time if there are none.
+func (f Feed) Updated() time.Time {
+	var updated time.Time
+	for _, e := range f.Entries {
+		if e.Updated.After(updated) {
+			updated = e.Updated
+		}
+	}
+	return updated
+}
+
+// MediaType returns the media type of a feed format.
+func MediaType(format string) string {
+	if format == RSS {
+		return "application/rss+xml"
+	}
+	return "application/atom+xml"
+}
+
+// ContentType returns the Content-Type header a feed is sent with.
+func ContentType(format string) string {
+	return MediaType(format) + "; charset=utf-8"
+}
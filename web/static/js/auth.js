@@ -139,6 +139,26 @@ function _applyNavUser(user) {
         newPageFab.classList.remove('hidden')
         newPageFab.classList.add('md:hidden')
     }
+
+    const notifications = document.getElementById('nav-notifications')
+    if (notifications) {
+        notifications.classList.remove('hidden')
+        _refreshUnreadBadge()
+    }
+}
+
+// Fetches the user's unread notification count into the nav badge, which
+// stays hidden when there's nothing unread.
+async function _refreshUnreadBadge() {
+    const badge = document.getElementById('nav-unread-badge')
+    if (!badge) return
+    try {
+        const resp = await fetch('/notifications/unread-count')
+        if (!resp.ok) return
+        const { unread } = await resp.json()
+        badge.textContent = unread > 99 ? '99+' : String(unread)
+        badge.classList.toggle('hidden', unread === 0)
+    } catch {}
 }
 
 function _applyNavGuest() {
@@ -167,6 +187,8 @@ function _applyNavGuest() {
         newPageFab.classList.remove('md:hidden')
         newPageFab.classList.add('hidden')
     }
+    const notifications = document.getElementById('nav-notifications')
+    if (notifications) notifications.classList.add('hidden')
 }
 
 function toggleUserDropdown() {
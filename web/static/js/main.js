@@ -134,6 +134,49 @@ document.addEventListener('DOMContentLoaded', function() {
             debounce(updatePreview, 300)
         })
 
+        // --- Draft autosave ---
+        // The edit page saves what's typed as the user's draft of the page,
+        // so it can be resumed if the session ends before it's submitted
+        const draftUrl = editTextarea.dataset.draftUrl
+        const draftStatus = document.getElementById('draft-status')
+        if (draftUrl) {
+            let draftTimer
+            let savedContent = editTextarea.value
+
+            async function saveDraft() {
+                if (editTextarea.value === savedContent) return
+                const content = editTextarea.value
+                const form = new FormData()
+                form.append('content', content)
+                const baseRevision = editTextarea.form.querySelector('input[name="base_revision"]')
+                if (baseRevision) {
+                    form.append('base_revision', baseRevision.value)
+                }
+
+                try {
+                    const response = await fetch(draftUrl, { method: 'POST', body: form })
+                    const data = await response.json()
+                    if (!response.ok) {
+                        throw new Error(data.error || 'Couldn\'t save the draft.')
+                    }
+                    savedContent = content
+                    if (draftStatus) {
+                        const time = new Date(data.updated_at).toLocaleTimeString([], { hour: 'numeric', minute: '2-digit' })
+                        draftStatus.textContent = 'Draft saved at ' + time
+                    }
+                } catch (error) {
+                    if (draftStatus) {
+                        draftStatus.textContent = error.message || 'Couldn\'t save the draft.'
+                    }
+                }
+            }
+
+            editTextarea.addEventListener('input', function() {
+                clearTimeout(draftTimer)
+                draftTimer = setTimeout(saveDraft, 2000)
+            })
+        }
+
         // --- Toolbar formatting helpers ---
 
         // Wraps selected text with a prefix/suffix (e.g. **bold**)
@@ -33,8 +33,14 @@ func (imageAltOnlyRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegistere
 }
 
 func ToHTML(content string) (string, error) {
+	return ToPageHTML(content, nil)
+}
+
+// ToPageHTML renders a page's markdown, showing links to the slugs in
+// missing as links to pages that don't exist yet.
+func ToPageHTML(content string, missing []string) (string, error) {
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.Table),
+		goldmark.WithExtensions(extension.Table, newWikiLinks(missing)),
 		goldmark.WithRendererOptions(
 			html.WithUnsafe(),
 		),
@@ -48,7 +54,7 @@ func ToHTML(content string) (string, error) {
 
 func ToHTMLPreview(content string) (string, error) {
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.Table),
+		goldmark.WithExtensions(extension.Table, newWikiLinks(nil)),
 		goldmark.WithRendererOptions(
 			renderer.WithNodeRenderers(
 				util.Prioritized(imageAltOnlyRenderer{}, 0),
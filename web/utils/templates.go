@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Templates are expanded here, before a page's markdown is rendered. The
+// wiki service keeps its own copy of this for the content it gives search.
+//
+// Templates are pages whose slug starts with TemplatePrefix. Another page
+// includes one with {{Office Hours}} or {{office-hours|room=Boone 210}},
+// and the template refers to its parameters as {{{room}}}, or
+// {{{room|TBA}}} with a default. Unnamed parameters are numbered from 1.
+const TemplatePrefix = "template-"
+
+// MaxTemplateDepth is how deeply templates can include other templates.
+const MaxTemplateDepth = 5
+
+var (
+	templateParam = regexp.MustCompile(`\{\{\{([^{}|]+)(?:\|([^{}]*))?\}\}\}`)
+	// fenced code blocks and inline code, where {{ is left alone
+	codeSpan = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)\\s*$|`[^`\n]+`")
+)
+
+// TemplateLookup returns a template page's content by its slug, and false
+// if there's no such page.
+type TemplateLookup func(slug string) (string, bool, error)
+
+// TemplateSlug returns the slug of the page a {{name}} include refers to.
+// The prefix is optional: {{Office Hours}} and {{template-office-hours}}
+// both mean the page template-office-hours.
+func TemplateSlug(name string) string {
+	slug := Slugify(strings.TrimSpace(name))
+	if slug == "" || strings.HasPrefix(slug, TemplatePrefix) {
+		return slug
+	}
+	return TemplatePrefix + slug
+}
+
+// ExpandTemplates replaces each {{...}} include in content with the
+// template's content, its parameters filled in. Templates including
+// templates are expanded too, up to MaxTemplateDepth deep. An include that
+// can't be expanded, because the template is missing, includes itself or is
+// nested too deeply, is replaced with a short error for the reader rather
+// than failing the page. Only lookup errors are returned.
+func ExpandTemplates(content string, lookup TemplateLookup) (string, error) {
+	cache := map[string]*string{}
+	cached := func(slug string) (string, bool, error) {
+		if content, ok := cache[slug]; ok {
+			return deref(content), content != nil, nil
+		}
+		content, found, err := lookup(slug)
+		if err != nil {
+			return "", false, err
+		}
+		if found {
+			cache[slug] = &content
+		} else {
+			cache[slug] = nil
+		}
+		return content, found, nil
+	}
+	return expandTemplates(content, nil, cached)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// stack holds the slugs of the templates being expanded, outermost first.
+func expandTemplates(content string, stack []string, lookup TemplateLookup) (string, error) {
+	var out strings.Builder
+	var err error
+	last := 0
+	for _, span := range codeSpan.FindAllStringIndex(content, -1) {
+		var expanded string
+		expanded, err = expandIncludes(content[last:span[0]], stack, lookup)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(expanded)
+		out.WriteString(content[span[0]:span[1]])
+		last = span[1]
+	}
+	expanded, err := expandIncludes(content[last:], stack, lookup)
+	if err != nil {
+		return "", err
+	}
+	out.WriteString(expanded)
+	return out.String(), nil
+}
+
+func expandIncludes(text string, stack []string, lookup TemplateLookup) (string, error) {
+	var err error
+	result := forEachInclude(text, func(inc templateInclude) string {
+		if err != nil {
+			return ""
+		}
+		slug := TemplateSlug(inc.name)
+		switch {
+		case slug == "":
+			return inc.raw
+		case slices.Contains(stack, slug):
+			return templateError(fmt.Sprintf("template loop: %s includes itself", slug))
+		case len(stack) >= MaxTemplateDepth:
+			return templateError(fmt.Sprintf("%s is nested more than %d templates deep", slug, MaxTemplateDepth))
+		}
+
+		body, found, lookupErr := lookup(slug)
+		if lookupErr != nil {
+			err = lookupErr
+			return ""
+		}
+		if !found {
+			return fmt.Sprintf(`<a class="template-missing" href="/pages/new?slug=%s">%s</a>`,
+				url.QueryEscape(slug), html.EscapeString("{{"+strings.TrimSpace(inc.name)+"}}"))
+		}
+
+		// parameters are expanded where they're written, so a template can
+		// be passed to itself as a parameter
+		params := map[string]string{}
+		position := 0
+		for _, arg := range inc.args {
+			arg, err = expandTemplates(arg, stack, lookup)
+			if err != nil {
+				return ""
+			}
+			key, value, named := strings.Cut(arg, "=")
+			if named && strings.TrimSpace(key) != "" && !strings.ContainsAny(key, "\n[]") {
+				params[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			} else {
+				position++
+				params[strconv.Itoa(position)] = strings.TrimSpace(arg)
+			}
+		}
+		body = templateParam.ReplaceAllStringFunc(body, func(m string) string {
+			sub := templateParam.FindStringSubmatch(m)
+			if value, ok := params[strings.TrimSpace(sub[1])]; ok {
+				return value
+			}
+			return sub[2]
+		})
+
+		expanded, expandErr := expandTemplates(strings.TrimSpace(body), append(slices.Clip(stack), slug), lookup)
+		if expandErr != nil {
+			err = expandErr
+			return ""
+		}
+		return expanded
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func templateError(msg string) string {
+	return fmt.Sprintf(`<span class="template-error">%s</span>`, html.EscapeString(msg))
+}
+
+type templateInclude struct {
+	raw  string
+	name string
+	args []string
+}
+
+// forEachInclude calls replace for each top-level {{...}} include in text
+// and returns text with the includes replaced by what it returned. Braces
+// and [[links]] nest, so a parameter can hold another include or a
+// [[slug|label]] link. {{{param}}} references aren't includes and are kept.
+func forEachInclude(text string, replace func(templateInclude) string) string {
+	var out strings.Builder
+	i := 0
+	for i < len(text) {
+		start := strings.Index(text[i:], "{{")
+		if start < 0 {
+			break
+		}
+		start += i
+		if strings.HasPrefix(text[start:], "{{{") {
+			end := strings.Index(text[start:], "}}}")
+			if end < 0 {
+				break
+			}
+			out.WriteString(text[i : start+end+3])
+			i = start + end + 3
+			continue
+		}
+
+		end, parts := matchInclude(text, start)
+		if end < 0 {
+			// unclosed, leave the rest as it is
+			break
+		}
+		out.WriteString(text[i:start])
+		out.WriteString(replace(templateInclude{raw: text[start:end], name: parts[0], args: parts[1:]}))
+		i = end
+	}
+	out.WriteString(text[i:])
+	return out.String()
+}
+
+// matchInclude finds the end of the include starting at text[start] and
+// splits what's inside on its top-level pipes. It returns -1 if the include
+// isn't closed or its name spans lines.
+func matchInclude(text string, start int) (int, []string) {
+	var parts []string
+	braces, brackets := 0, 0
+	partStart := start + 2
+	for i := start + 2; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{{"):
+			end := strings.Index(text[i:], "}}}")
+			if end < 0 {
+				return -1, nil
+			}
+			i += end + 2
+		case strings.HasPrefix(text[i:], "{{"):
+			braces++
+			i++
+		case strings.HasPrefix(text[i:], "}}") && braces > 0:
+			braces--
+			i++
+		case strings.HasPrefix(text[i:], "}}"):
+			parts = append(parts, text[partStart:i])
+			if strings.Contains(parts[0], "\n") {
+				return -1, nil
+			}
+			return i + 2, parts
+		case strings.HasPrefix(text[i:], "[["):
+			brackets++
+			i++
+		case strings.HasPrefix(text[i:], "]]") && brackets > 0:
+			brackets--
+			i++
+		case text[i] == '|' && braces == 0 && brackets == 0:
+			parts = append(parts, text[partStart:i])
+			partStart = i + 1
+		}
+	}
+	return -1, nil
+}
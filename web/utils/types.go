@@ -7,14 +7,35 @@ import (
 )
 
 type Page struct {
-	UUID         uuid.UUID  `json:"uuid"`
-	Slug         string     `json:"slug"`
-	Name         string     `json:"name"`
-	ArchiveDate  *time.Time `json:"archive_date"`
-	LastEditUUID *uuid.UUID `json:"last_edit"`
-	LastEditTime time.Time  `json:"last_edit_time"`
-	Content      string     `json:"content"`
-	Categories   []Category `json:"categories"`
+	UUID           uuid.UUID   `json:"uuid"`
+	Slug           string      `json:"slug"`
+	Name           string      `json:"name"`
+	ArchiveDate    *time.Time  `json:"archive_date"`
+	LastEditUUID   *uuid.UUID  `json:"last_edit"`
+	LastEditTime   time.Time   `json:"last_edit_time"`
+	Content        string      `json:"content"`
+	Categories     []Category  `json:"categories"`
+	RedirectedFrom string      `json:"redirected_from,omitempty"`
+	MissingLinks   []string    `json:"missing_links"`
+	Backlinks      []PageLink  `json:"backlinks"`
+	Protection     *Protection `json:"protection,omitempty"`
+}
+
+// Protection limits who can edit a page. Level is "semi", "moderator" or
+// "locked"; unprotected pages have none.
+type Protection struct {
+	Level          string     `json:"level"`
+	MinAccountDays int        `json:"min_account_days"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	ProtectedBy    string     `json:"protected_by"`
+	Reason         string     `json:"reason"`
+}
+
+// PageLink is a page that links to another, for "what links here".
+type PageLink struct {
+	UUID uuid.UUID `json:"uuid"`
+	Slug string    `json:"slug"`
+	Name string    `json:"name"`
 }
 
 type PageInfoPrev struct {
@@ -46,43 +67,52 @@ type CategoryFlat struct {
 // RevisionList represents a revision from the list endpoint (/pages/{id}/revisions or /revisions)
 // Uses "date_time" field name and nullable fields as returned by the list API
 type RevisionList struct {
-	UUID        *uuid.UUID `json:"uuid"`
-	PageId      *uuid.UUID `json:"page_id"`
-	DateTime    *time.Time `json:"date_time"`
-	Author      *string    `json:"author"`
-	Slug        string     `json:"slug"`
-	Name        string     `json:"name"`
-	ArchiveDate *time.Time `json:"archive_date"`
-	DeletedAt   *time.Time `json:"deleted_at"`
+	UUID         *uuid.UUID `json:"uuid"`
+	PageId       *uuid.UUID `json:"page_id"`
+	DateTime     *time.Time `json:"date_time"`
+	Author       *string    `json:"author"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	ArchiveDate  *time.Time `json:"archive_date"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+	RevertedFrom *uuid.UUID `json:"reverted_from"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
 }
 
 // RevisionDetail represents a revision from the detail endpoint (/pages/{id}/revisions/{revId})
 // Uses "rev_date_time" field name and non-nullable fields, includes content
 type RevisionDetail struct {
-	UUID        uuid.UUID  `json:"uuid"`
-	PageId      uuid.UUID  `json:"page_id"`
-	RevDateTime time.Time  `json:"rev_date_time"`
-	Author      string     `json:"author"`
-	Slug        string     `json:"slug"`
-	Name        string     `json:"name"`
-	ArchiveDate *time.Time `json:"archive_date"`
-	DeletedAt   *time.Time `json:"deleted_at"`
-	Content     string     `json:"content"`
+	UUID         uuid.UUID  `json:"uuid"`
+	PageId       uuid.UUID  `json:"page_id"`
+	RevDateTime  time.Time  `json:"rev_date_time"`
+	Author       string     `json:"author"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	ArchiveDate  *time.Time `json:"archive_date"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+	RevertedFrom *uuid.UUID `json:"reverted_from"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
+	Content      string     `json:"content"`
 }
 
 // Revision represents a page revision from the API
 // Deprecated: Use RevisionList for list endpoints or RevisionDetail for detail endpoints
 // Note: The API uses different field names for list vs detail endpoints
 type Revision struct {
-	UUID        uuid.UUID  `json:"uuid"`
-	PageId      uuid.UUID  `json:"page_id"`
-	RevDateTime time.Time  `json:"rev_date_time"`
-	Author      string     `json:"author"`
-	Slug        string     `json:"slug"`
-	Name        string     `json:"name"`
-	ArchiveDate *time.Time `json:"archive_date"`
-	DeletedAt   *time.Time `json:"deleted_at"`
-	Content     string     `json:"content"`
+	UUID         uuid.UUID  `json:"uuid"`
+	PageId       uuid.UUID  `json:"page_id"`
+	RevDateTime  time.Time  `json:"rev_date_time"`
+	Author       string     `json:"author"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	ArchiveDate  *time.Time `json:"archive_date"`
+	DeletedAt    *time.Time `json:"deleted_at"`
+	RevertedFrom *uuid.UUID `json:"reverted_from"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
+	Content      string     `json:"content"`
 }
 
 // ToRevision converts a RevisionList to the deprecated Revision type for backward compatibility
@@ -109,30 +139,36 @@ func (rl RevisionList) ToRevision() Revision {
 	}
 
 	return Revision{
-		UUID:        revUUID,
-		PageId:      pageId,
-		RevDateTime: dateTime,
-		Author:      author,
-		Slug:        rl.Slug,
-		Name:        rl.Name,
-		ArchiveDate: rl.ArchiveDate,
-		DeletedAt:   rl.DeletedAt,
-		Content:     "",
+		UUID:         revUUID,
+		PageId:       pageId,
+		RevDateTime:  dateTime,
+		Author:       author,
+		Slug:         rl.Slug,
+		Name:         rl.Name,
+		ArchiveDate:  rl.ArchiveDate,
+		DeletedAt:    rl.DeletedAt,
+		RevertedFrom: rl.RevertedFrom,
+		Summary:      rl.Summary,
+		Minor:        rl.Minor,
+		Content:      "",
 	}
 }
 
 // ToRevision converts a RevisionDetail to the deprecated Revision type for backward compatibility
 func (rd RevisionDetail) ToRevision() Revision {
 	return Revision{
-		UUID:        rd.UUID,
-		PageId:      rd.PageId,
-		RevDateTime: rd.RevDateTime,
-		Author:      rd.Author,
-		Slug:        rd.Slug,
-		Name:        rd.Name,
-		ArchiveDate: rd.ArchiveDate,
-		DeletedAt:   rd.DeletedAt,
-		Content:     rd.Content,
+		UUID:         rd.UUID,
+		PageId:       rd.PageId,
+		RevDateTime:  rd.RevDateTime,
+		Author:       rd.Author,
+		Slug:         rd.Slug,
+		Name:         rd.Name,
+		ArchiveDate:  rd.ArchiveDate,
+		DeletedAt:    rd.DeletedAt,
+		RevertedFrom: rd.RevertedFrom,
+		Summary:      rd.Summary,
+		Minor:        rd.Minor,
+		Content:      rd.Content,
 	}
 }
 
@@ -144,3 +180,169 @@ func RevisionListToRevisions(list []RevisionList) []Revision {
 	}
 	return result
 }
+
+// EditConflict is returned by the API (409) when an edit made against an older
+// revision overlaps changes that were saved in the meantime
+type EditConflict struct {
+	BaseRevision    uuid.UUID      `json:"base_revision"`
+	CurrentRevision uuid.UUID      `json:"current_revision"`
+	Merged          string         `json:"merged"`
+	Conflicts       []ConflictHunk `json:"conflicts"`
+}
+
+// ConflictHunk is a single region both edits changed differently
+type ConflictHunk struct {
+	Line    int    `json:"line"`
+	Base    string `json:"base"`
+	Current string `json:"current"`
+	Yours   string `json:"yours"`
+}
+
+// DeletedPage is a soft-deleted page as listed for moderators
+type DeletedPage struct {
+	UUID      uuid.UUID `json:"uuid"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+}
+
+// WantedPage is a slug that pages link to but no page has.
+type WantedPage struct {
+	Slug  string `json:"slug"`
+	Links int    `json:"links"`
+}
+
+// DeletedLink is a link from a page to a deleted page.
+type DeletedLink struct {
+	Page       PageLink  `json:"page"`
+	TargetSlug string    `json:"target_slug"`
+	Target     PageLink  `json:"target"`
+	DeletedAt  time.Time `json:"deleted_at"`
+}
+
+// Draft is a user's unsaved edit of a page, kept by the wiki so it
+// survives the browser closing.
+type Draft struct {
+	UUID         uuid.UUID  `json:"uuid"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	BaseRevision *uuid.UUID `json:"base_revision"`
+	Content      string     `json:"content"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// Report is one of the wiki's maintenance reports.
+type Report struct {
+	Name  string
+	Title string
+}
+
+// BlameLine is a line of a page with the revision that last changed it
+type BlameLine struct {
+	Line     int       `json:"line"`
+	Content  string    `json:"content"`
+	Revision uuid.UUID `json:"revision"`
+	Author   string    `json:"author"`
+	DateTime time.Time `json:"date_time"`
+}
+
+// PageBlame represents the response from the blame endpoint (/pages/{id}/blame)
+type PageBlame struct {
+	PageId   uuid.UUID   `json:"page_id"`
+	Revision uuid.UUID   `json:"revision"`
+	Lines    []BlameLine `json:"lines"`
+}
+
+// PendingRevision is an edit held by the moderation service for review
+type PendingRevision struct {
+	ID           uuid.UUID  `json:"id"`
+	PageID       uuid.UUID  `json:"page_id"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	Author       string     `json:"author"`
+	BaseRevision *uuid.UUID `json:"base_revision"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
+	HoldReason   string     `json:"hold_reason"`
+	Status       string     `json:"status"`
+	SubmittedAt  time.Time  `json:"submitted_at"`
+	ReviewedBy   *string    `json:"reviewed_by"`
+	RejectReason *string    `json:"reject_reason"`
+}
+
+// PendingDiff is a held edit's changes against the revision it was based on
+type PendingDiff struct {
+	CurrentRevision *uuid.UUID `json:"current_revision"`
+	Stale           bool       `json:"stale"`
+	Diff            string     `json:"diff"`
+}
+
+// Change is a revision as listed in recent changes
+type Change struct {
+	UUID         uuid.UUID  `json:"uuid"`
+	PageId       uuid.UUID  `json:"page_id"`
+	DateTime     time.Time  `json:"date_time"`
+	Author       string     `json:"author"`
+	Slug         string     `json:"slug"`
+	Name         string     `json:"name"`
+	Type         string     `json:"type"`
+	Summary      string     `json:"summary"`
+	Minor        bool       `json:"minor"`
+	RevertedFrom *uuid.UUID `json:"reverted_from"`
+	DiffStats    *DiffStats `json:"diff_stats"`
+}
+
+// DiffStats counts the lines a change added and removed
+type DiffStats struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// RecentChanges is one page of recent changes. NextCursor is empty on the last page.
+type RecentChanges struct {
+	Changes    []Change `json:"changes"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+// Watch is a page or category a user watches. Exactly one of PageId and
+// CategoryId is set.
+type Watch struct {
+	UUID         uuid.UUID  `json:"uuid"`
+	PageId       *uuid.UUID `json:"page_id"`
+	Slug         *string    `json:"slug"`
+	Name         *string    `json:"name"`
+	CategoryId   *int       `json:"category_id"`
+	CategorySlug *string    `json:"category_slug"`
+	CategoryName *string    `json:"category_name"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// WatchTarget is something the logged-in user can watch from the page it's
+// shown on. Field and Value are what to post to start watching it; Watch is
+// set if they already do.
+type WatchTarget struct {
+	Field string
+	Value string
+	Watch *Watch
+}
+
+// Notification tells a user about a change to something they watch
+type Notification struct {
+	UUID       uuid.UUID  `json:"uuid"`
+	PageId     uuid.UUID  `json:"page_id"`
+	Slug       string     `json:"slug"`
+	Name       string     `json:"name"`
+	RevisionId *uuid.UUID `json:"revision_id"`
+	Kind       string     `json:"kind"`
+	Actor      string     `json:"actor"`
+	Summary    string     `json:"summary"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ReadAt     *time.Time `json:"read_at"`
+}
+
+// Inbox is a page of a user's notifications and how many they haven't read in all
+type Inbox struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        int            `json:"unread"`
+}
@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindWikiLink is the node kind of a [[wiki link]].
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// WikiLink is a [[Page Name]] or [[slug|label]] link. Its children are the
+// label.
+type WikiLink struct {
+	ast.BaseInline
+	Slug string
+	Name string
+}
+
+func (n *WikiLink) Kind() ast.NodeKind {
+	return KindWikiLink
+}
+
+func (n *WikiLink) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Slug": n.Slug}, nil)
+}
+
+// Slugify turns a page name into the slug a [[Page Name]] link goes to. It
+// has to match the wiki service's, which records the links.
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		case r == '\'' || r == '’':
+			// "Boone's" is "boones", not "boone-s"
+		default:
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+type wikiLinkParser struct{}
+
+func (wikiLinkParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (wikiLinkParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	if !bytes.HasPrefix(line, []byte("[[")) {
+		return nil
+	}
+	end := bytes.Index(line[2:], []byte("]]"))
+	if end <= 0 {
+		return nil
+	}
+	inner := line[2 : 2+end]
+	if bytes.ContainsAny(inner, "[]\n") {
+		return nil
+	}
+
+	target, labelStart := inner, 2
+	if i := bytes.IndexByte(inner, '|'); i >= 0 {
+		target, labelStart = inner[:i], 2+i+1
+	}
+	name, _, _ := strings.Cut(string(target), "#")
+	slug := Slugify(strings.TrimSpace(name))
+	if slug == "" {
+		return nil
+	}
+
+	link := &WikiLink{Slug: slug, Name: strings.TrimSpace(name)}
+	label := text.NewSegment(segment.Start+labelStart, segment.Start+2+end)
+	if label.Len() == 0 {
+		// [[slug|]] shows the target
+		label = text.NewSegment(segment.Start+2, segment.Start+2+len(target))
+	}
+	link.AppendChild(link, ast.NewTextSegment(label))
+	block.Advance(2 + end + 2)
+	return link
+}
+
+// wikiLinkRenderer renders wiki links, marking links to the slugs in missing
+// so readers can tell the page doesn't exist yet. Those go to the editor to
+// create it instead.
+type wikiLinkRenderer struct {
+	missing map[string]bool
+}
+
+func (r wikiLinkRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, func(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+		n := node.(*WikiLink)
+		if !entering {
+			w.WriteString("</a>")
+			return ast.WalkContinue, nil
+		}
+		if r.missing[n.Slug] {
+			href := fmt.Sprintf("/pages/new?slug=%s&name=%s", url.QueryEscape(n.Slug), url.QueryEscape(n.Name))
+			fmt.Fprintf(w, `<a href="%s" class="wiki-link wiki-link-missing" title="This page doesn't exist yet">`, util.EscapeHTML([]byte(href)))
+		} else {
+			fmt.Fprintf(w, `<a href="/pages/%s" class="wiki-link">`, util.EscapeHTML([]byte(n.Slug)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+var pageLinkDestination = regexp.MustCompile(`^/pages/([a-z0-9]+(?:-[a-z0-9]+)*)(?:[#?].*)?$`)
+
+// missingPageLinks marks plain markdown links to /pages/<slug> for a missing
+// slug the same way as wiki links.
+type missingPageLinks struct {
+	missing map[string]bool
+}
+
+func (t missingPageLinks) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		link, ok := node.(*ast.Link)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+		m := pageLinkDestination.FindSubmatch(link.Destination)
+		if m != nil && t.missing[string(m[1])] {
+			link.SetAttributeString("class", []byte("wiki-link-missing"))
+			link.SetAttributeString("title", []byte("This page doesn't exist yet"))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// wikiLinks is the goldmark extension for [[wiki links]]. The slugs in
+// missing are pages that don't exist.
+type wikiLinks struct {
+	missing map[string]bool
+}
+
+func (e wikiLinks) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		// ahead of the link parser, which also starts at '['
+		parser.WithInlineParsers(util.Prioritized(wikiLinkParser{}, 199)),
+		parser.WithASTTransformers(util.Prioritized(missingPageLinks{e.missing}, 100)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(wikiLinkRenderer{e.missing}, 100)),
+	)
+}
+
+func newWikiLinks(missing []string) wikiLinks {
+	e := wikiLinks{missing: make(map[string]bool, len(missing))}
+	for _, slug := range missing {
+		e.missing[slug] = true
+	}
+	return e
+}
@@ -18,7 +18,14 @@ import (
 // GetCreatePage renders the "Create New Page" form.
 func GetCreatePage(c *gin.Context) {
 	categories, _ := getCategories()
-	createContent := wikipages.WikiCreateContent("", "", "", "# Title\n\nContent here...", categories, []string{})
+	// links to missing pages fill in the page they point to
+	name := c.Query("name")
+	slug := c.Query("slug")
+	content := "# Title\n\nContent here..."
+	if name != "" {
+		content = fmt.Sprintf("# %s\n\nContent here...", name)
+	}
+	createContent := wikipages.WikiCreateContent("", name, slug, content, categories, []string{})
 	component := components.Page("Create New Page", createContent)
 	component.Render(context.Background(), c.Writer)
 }
@@ -0,0 +1,117 @@
+package wiki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"web/auth"
+	"web/config"
+	"web/templates/components"
+	wikipages "web/templates/wiki-pages"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeletedPages renders the moderator list of deleted pages
+func GetDeletedPages(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+
+	req, err := http.NewRequestWithContext(
+		c.Request.Context(),
+		http.MethodGet,
+		fmt.Sprintf("%s/deleted-pages?index=0&count=100", config.WikiURL),
+		nil,
+	)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	tokenCookie, err := c.Cookie("auth_token")
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.AbortWithError(resp.StatusCode, fmt.Errorf("failed to fetch deleted pages"))
+		return
+	}
+
+	var pages []utils.DeletedPage
+	if err := json.NewDecoder(resp.Body).Decode(&pages); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	restoreFailed := c.Query("error") == "restore_failed"
+	content := wikipages.WikiDeletedContent(pages, restoreFailed)
+	components.Page("Deleted Pages", content).Render(context.Background(), c.Writer)
+}
+
+// PostRestorePage handles the restore form submission
+func PostRestorePage(c *gin.Context) {
+	id := c.Param("id")
+
+	// Get user email from context (set by RequireRole middleware)
+	userValue, exists := c.Get("user")
+	if !exists {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	user, ok := userValue.(*auth.User)
+	if !ok {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	// Build form data
+	formData := url.Values{}
+	formData.Set("slug", id)
+	formData.Set("user", user.Email)
+
+	// Forward to API layer
+	req, err := http.NewRequestWithContext(
+		c.Request.Context(),
+		http.MethodPost,
+		fmt.Sprintf("%s/pages/%s/restore", config.WikiURL, id),
+		bytes.NewBufferString(formData.Encode()),
+	)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	tokenCookie, err := c.Cookie("auth_token")
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?saved=true", id))
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/deleted-pages?error=restore_failed")
+}
@@ -0,0 +1,127 @@
+package wiki
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"web/config"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userRequest builds a request to the API layer as the logged-in user, for
+// routes that act on their own drafts, watches and notifications.
+func userRequest(c *gin.Context, method string, path string, form url.Values) (*http.Request, error) {
+	token, err := c.Cookie(authCookieName)
+	if err != nil || token == "" {
+		return nil, fmt.Errorf("not logged in")
+	}
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, config.WikiURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// fetchDraft returns the user's draft of a page, or nil if they don't have
+// one.
+func fetchDraft(c *gin.Context, id string) (*utils.Draft, error) {
+	req, err := userRequest(c, http.MethodGet, fmt.Sprintf("/pages/%s/draft", id), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching draft: status %d", resp.StatusCode)
+	}
+
+	var draft utils.Draft
+	if err := json.NewDecoder(resp.Body).Decode(&draft); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// discardDraft deletes the user's draft of a page, if they have one.
+func discardDraft(c *gin.Context, id string) error {
+	req, err := userRequest(c, http.MethodPost, fmt.Sprintf("/pages/%s/draft/delete", id), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("discarding draft: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PostSaveDraft autosaves the editor's content as the user's draft of the
+// page. It answers with JSON for the editor's script.
+func PostSaveDraft(c *gin.Context) {
+	id := c.Param("id")
+	form := url.Values{
+		"content":       {c.PostForm("content")},
+		"base_revision": {c.PostForm("base_revision")},
+	}
+
+	req, err := userRequest(c, http.MethodPost, fmt.Sprintf("/pages/%s/draft", id), form)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "You must be logged in to save drafts."})
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "The wiki service is unreachable."})
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.JSON(resp.StatusCode, gin.H{"error": "Couldn't save the draft."})
+		return
+	}
+
+	var draft utils.Draft
+	if err := json.NewDecoder(resp.Body).Decode(&draft); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Couldn't read the saved draft."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"updated_at": draft.UpdatedAt})
+}
+
+// PostDiscardDraft deletes the user's draft of a page and goes back to
+// editing the page as it is.
+func PostDiscardDraft(c *gin.Context) {
+	id := c.Param("id")
+	if err := discardDraft(c, id); err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s/edit", id))
+}
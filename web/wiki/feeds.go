@@ -0,0 +1,204 @@
+package wiki
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"web/config"
+	"web/feeds"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// feedMaxAge is how long readers and proxies can cache a feed
+const feedMaxAge = 5 * time.Minute
+
+// GetRecentChangesFeed serves the site-wide recent changes as a feed. It
+// takes the same filters as the recent changes page.
+func GetRecentChangesFeed(c *gin.Context) {
+	filters := url.Values{}
+	for _, name := range changeFilters {
+		if value := c.Query(name); value != "" {
+			filters.Set(name, value)
+		}
+	}
+	link := "/recent-changes"
+	if len(filters) > 0 {
+		link += "?" + filters.Encode()
+	}
+	serveChangesFeed(c, "Recent changes - TreveccaPedia", link, filters)
+}
+
+// GetPageFeed serves a page's revision history as a feed.
+func GetPageFeed(c *gin.Context) {
+	id := c.Param("id")
+	name := id
+	if page, err := fetchPageData(id); err == nil && page.Name != "" {
+		id, name = page.Slug, page.Name
+	}
+	filters := url.Values{"page": {id}}
+	serveChangesFeed(c, name+" - Revision history", fmt.Sprintf("/pages/%s/history", id), filters)
+}
+
+// GetCategoryFeed serves the pages created in a category or any of its
+// subcategories as a feed.
+func GetCategoryFeed(c *gin.Context) {
+	slug := strings.Trim(c.Param("path"), "/")
+	name := slug
+	categories, err := getCategories()
+	if err == nil {
+		for _, cat := range flattenCategories(categories) {
+			if cat.FullSlug == slug {
+				name = cat.Name
+				break
+			}
+		}
+	}
+	filters := url.Values{"category": {slug}, "type": {"create"}}
+	serveChangesFeed(c, "New pages in "+name, "/pages?category="+url.QueryEscape(slug), filters)
+}
+
+func serveChangesFeed(c *gin.Context, title string, link string, filters url.Values) {
+	format := c.DefaultQuery("format", feeds.Atom)
+	if format != feeds.Atom && format != feeds.RSS {
+		c.String(http.StatusBadRequest, "format must be atom or rss")
+		return
+	}
+
+	filters.Set("diff_stats", "true")
+	changes, err := fetchRecentChanges(filters, "")
+	var rejected *rejectedFilter
+	if errors.As(err, &rejected) {
+		c.String(rejected.status, rejected.message)
+		return
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+
+	site := siteURL(c)
+	feed := feeds.Feed{
+		ID:      site + c.Request.URL.RequestURI(),
+		Title:   title,
+		Link:    site + link,
+		SelfURL: site + c.Request.URL.RequestURI(),
+	}
+	for _, change := range changes.Changes {
+		feed.Entries = append(feed.Entries, changeEntry(site, change))
+	}
+
+	var body []byte
+	if format == feeds.RSS {
+		body, err = feeds.WriteRSS(feed)
+	} else {
+		body, err = feeds.WriteAtom(feed)
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	serveFeed(c, body, feeds.ContentType(format), feed.Updated())
+}
+
+// serveFeed sends a feed with headers that let readers cache it and ask
+// again with If-None-Match or If-Modified-Since, which get a 304 if the
+// feed hasn't changed.
+func serveFeed(c *gin.Context, body []byte, contentType string, updated time.Time) {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(feedMaxAge.Seconds())))
+	c.Header("ETag", etag)
+	if !updated.IsZero() {
+		c.Header("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.GetHeader("If-None-Match"); match != "" {
+		if etagMatches(match, etag) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !updated.IsZero() {
+		// Last-Modified only has whole seconds
+		if !updated.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, contentType, body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// validators match too, as they should for a GET.
+func etagMatches(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// changeEntry turns a change into a feed entry linking to the revision.
+func changeEntry(site string, change utils.Change) feeds.Entry {
+	username, _, _ := strings.Cut(change.Author, "@")
+	entry := feeds.Entry{
+		ID:         "urn:uuid:" + change.UUID.String(),
+		Title:      change.Name,
+		Link:       fmt.Sprintf("%s/pages/%s/history/%s", site, change.Slug, change.UUID),
+		AuthorName: username,
+		AuthorURI:  fmt.Sprintf("%s/users/%s", site, url.PathEscape(username)),
+		Updated:    change.DateTime,
+		Summary:    changeSummary(change),
+	}
+	switch change.Type {
+	case "create":
+		entry.Title = "New page: " + change.Name
+	case "delete":
+		entry.Title = "Deleted: " + change.Name
+		// the page's history is gone with it
+		entry.Link = site + "/recent-changes"
+	}
+	return entry
+}
+
+// changeSummary is the editor's summary and the size of the change,
+// like "Fix office hours (+3 -1 lines)".
+func changeSummary(change utils.Change) string {
+	summary := change.Summary
+	if summary == "" && change.RevertedFrom != nil {
+		summary = "Restored an earlier version"
+	}
+	if change.DiffStats == nil || change.Type == "delete" {
+		return summary
+	}
+	stats := fmt.Sprintf("+%d -%d lines", change.DiffStats.Added, change.DiffStats.Removed)
+	if change.Minor {
+		stats += ", minor"
+	}
+	if summary == "" {
+		return stats
+	}
+	return fmt.Sprintf("%s (%s)", summary, stats)
+}
+
+// siteURL is the address links in feeds start with.
+func siteURL(c *gin.Context) string {
+	if config.SiteURL != "" {
+		return strings.TrimSuffix(config.SiteURL, "/")
+	}
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"web/auth"
 	"web/config"
 	categorytemplates "web/templates/category"
@@ -44,12 +45,32 @@ func GetPage(c *gin.Context) {
 		return
 	}
 
+	// Old slugs and aliases move permanently to the page's current slug
+	if page.RedirectedFrom != "" {
+		c.Redirect(http.StatusMovedPermanently, fmt.Sprintf("/pages/%s?redirected_from=%s", page.Slug, url.QueryEscape(page.RedirectedFrom)))
+		return
+	}
+
+	// Templates are filled in before the markdown is rendered
+	page.Content, err = utils.ExpandTemplates(page.Content, pageTemplates(c.Request.Context()))
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, fmt.Errorf("Couldn't fill in templates: %w\n", err))
+		return
+	}
+
 	// Fetch categories for this page
 	categories, _ := getPageCategories(page.UUID.String())
 	page.Categories = categories
 
+	backlinks, _ := getPageBacklinks(page.UUID.String())
+	page.Backlinks = backlinks
+
 	saved := c.Query("saved") == "true"
-	entryContent := wikipages.WikiEntryContent(page, saved, isModerator)
+	pending := c.Query("pending") == "true"
+	redirectedFrom := c.Query("redirected_from")
+	protectionFailed := c.Query("error") == "protection"
+	watch := pageWatchTarget(c, page)
+	entryContent := wikipages.WikiEntryContent(page, saved, pending, isModerator, redirectedFrom, protectionFailed, watch)
 	component := components.Page(page.Name, entryContent)
 	component.Render(context.Background(), c.Writer)
 }
@@ -90,6 +111,8 @@ func GetCategoryPages(c *gin.Context) {
 		}
 	}
 
+	watch := categoryWatchTarget(c, categorySlug)
+
 	title := categoryName
 	if categorySlug == "" {
 		title = "All Pages"
@@ -99,13 +122,13 @@ func GetCategoryPages(c *gin.Context) {
 	if c.GetHeader("HX-Request") == "true" {
 		// Return only the content partial (no full page wrapper)
 		// The hx-select attribute will extract just #category-main-content
-		content := categorytemplates.CategoryContent(categorySlug, categoryName, categories, pages)
+		content := categorytemplates.CategoryContent(categorySlug, categoryName, categories, pages, watch)
 		content.Render(context.Background(), c.Writer)
 		return
 	}
 
 	// Full page render for non-htmx requests
-	content := categorytemplates.CategoryContent(categorySlug, categoryName, categories, pages)
+	content := categorytemplates.CategoryContent(categorySlug, categoryName, categories, pages, watch)
 	component := components.Page(title, content)
 	component.Render(context.Background(), c.Writer)
 }
@@ -203,6 +226,27 @@ func getPageCategories(pageId string) ([]utils.Category, error) {
 	return categories, nil
 }
 
+func getPageBacklinks(pageId string) ([]utils.PageLink, error) {
+	resp, err := http.Get(fmt.Sprintf("%s/pages/%s/backlinks", config.WikiURL, pageId))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []utils.PageLink
+	err = json.Unmarshal(body, &links)
+	if err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
 func GetEditPage(c *gin.Context) {
 	id := c.Param("id")
 	resp, err := http.Get(fmt.Sprintf("%s/pages/%s", config.WikiURL, id))
@@ -225,7 +269,22 @@ func GetEditPage(c *gin.Context) {
 		return
 	}
 
-	editContent := wikipages.WikiEditContent(page, "")
+	// Offer to pick up where the user left off. A draft that matches the
+	// page isn't worth mentioning.
+	draft, _ := fetchDraft(c, id)
+	if draft != nil && draft.Content == page.Content {
+		draft = nil
+	}
+	resumed := draft != nil && c.Query("draft") == "resume"
+	if resumed {
+		// edit from the draft, merging in what's changed since it was started
+		page.Content = draft.Content
+		if draft.BaseRevision != nil {
+			page.LastEditUUID = draft.BaseRevision
+		}
+	}
+
+	editContent := wikipages.WikiEditContent(page, "", draft, resumed)
 	component := components.Page("Editing: "+page.Name, editContent)
 	component.Render(context.Background(), c.Writer)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"web/auth"
 	"web/config"
 	"web/templates/components"
 	wikipages "web/templates/wiki-pages"
@@ -22,6 +23,10 @@ func GetPageHistory(c *gin.Context) {
 	id := c.Param("id")
 	revId := c.Param("revId")
 
+	// Contributors can restore older revisions
+	user, _ := auth.GetUserFromContext(c)
+	isContributor := auth.HasRole(user, "contributor")
+
 	// Get current page data
 	page, err := fetchPageData(id)
 	if err != nil {
@@ -84,15 +89,30 @@ func GetPageHistory(c *gin.Context) {
 	// Highlight changes and convert to HTML
 	highlightedContent, hasChanges := highlightChanges(currentRevision.Content, previousRevision)
 
+	// In blame mode each line is shown with the revision that last changed it
+	var blame *utils.PageBlame
+	if c.Query("view") == "blame" {
+		pageBlame, err := fetchBlame(id, currentRevision.UUID.String())
+		if err != nil {
+			c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+		blame = &pageBlame
+	}
+
 	// Convert new types to deprecated Revision type for template compatibility
 	revisionsForTemplate := utils.RevisionListToRevisions(revisions)
 	currentRevisionForTemplate := currentRevision.ToRevision()
 
+	// The latest revision is what the page already shows, so there's nothing to restore
+	canRestore := isContributor && page.LastEditUUID != nil && *page.LastEditUUID != currentRevision.UUID
+	revertError := revertErrors[c.Query("error")]
+
 	// Check if HTMX request (for partial content update)
 	if c.GetHeader("HX-Request") == "true" {
 		// Return article content AND updated timeline selection
 		// Article replaces #article-content via hx-target
-		articleContent := wikipages.WikiHistoryArticle(page, currentRevisionForTemplate, highlightedContent, revisionNumber, hasChanges)
+		articleContent := wikipages.WikiHistoryArticle(page, currentRevisionForTemplate, highlightedContent, revisionNumber, hasChanges, canRestore, revertError, blame)
 		articleContent.Render(context.Background(), c.Writer)
 
 		// Timeline updates selection via hx-swap-oob
@@ -102,11 +122,17 @@ func GetPageHistory(c *gin.Context) {
 	}
 
 	// Full page render
-	historyContent := wikipages.WikiHistoryContent(page, revisionsForTemplate, currentRevisionForTemplate, highlightedContent, revisionNumber, hasChanges)
+	historyContent := wikipages.WikiHistoryContent(page, revisionsForTemplate, currentRevisionForTemplate, highlightedContent, revisionNumber, hasChanges, canRestore, revertError, blame)
 	component := components.Page(page.Name+" - Revision History", historyContent)
 	component.Render(context.Background(), c.Writer)
 }
 
+// revertErrors are the messages shown on a revision that couldn't be restored
+var revertErrors = map[string]string{
+	"revert_failed": "This version couldn't be restored. Its slug may now belong to another page.",
+	"protected":     "This page is protected, so you can't restore a version of it.",
+}
+
 // GetTimelinePartial returns more timeline items for infinite scroll
 func GetTimelinePartial(c *gin.Context) {
 	id := c.Param("id")
@@ -202,6 +228,33 @@ func fetchRevision(id, revId string) (utils.RevisionDetail, error) {
 	return revision, nil
 }
 
+// fetchBlame gets the line-by-line authorship of a revision from API
+func fetchBlame(id, revId string) (utils.PageBlame, error) {
+	url := fmt.Sprintf("%s/pages/%s/blame?rev=%s", config.WikiURL, id, revId)
+	resp, err := http.Get(url)
+	if err != nil {
+		return utils.PageBlame{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return utils.PageBlame{}, fmt.Errorf("blame request failed with status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return utils.PageBlame{}, err
+	}
+
+	var blame utils.PageBlame
+	err = json.Unmarshal(body, &blame)
+	if err != nil {
+		return utils.PageBlame{}, err
+	}
+
+	return blame, nil
+}
+
 // highlightChanges compares content and shows deleted text with strikethrough
 // Returns the HTML content with deletions shown and a boolean indicating if there are changes
 func highlightChanges(currentContent string, previousRevision *utils.RevisionDetail) (string, bool) {
@@ -8,6 +8,8 @@ import (
 	"io"
 	"mime/multipart"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 	"web/config"
 	"web/templates/components"
@@ -15,10 +17,17 @@ import (
 	"web/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const authCookieName = "auth_token"
 
+// Markers the wiki service puts around unresolved sections of a merged edit.
+const (
+	conflictStartMarker = "<<<<<<< current\n"
+	conflictEndMarker   = ">>>>>>> yours\n"
+)
+
 // wikiClient is used for upstream calls to the API layer.
 var wikiClient = &http.Client{Timeout: 15 * time.Second}
 
@@ -78,7 +87,7 @@ func PostEditPage(c *gin.Context) {
 			c.AbortWithError(http.StatusBadGateway, fetchErr)
 			return
 		}
-		editContent := wikipages.WikiEditContent(page, authErr.Error())
+		editContent := wikipages.WikiEditContent(page, authErr.Error(), nil, false)
 		component := components.Page("Editing: "+page.Name, editContent)
 		component.Render(context.Background(), c.Writer)
 		return
@@ -92,7 +101,7 @@ func PostEditPage(c *gin.Context) {
 			c.AbortWithError(http.StatusBadGateway, fetchErr)
 			return
 		}
-		editContent := wikipages.WikiEditContent(page, "Content cannot be empty.")
+		editContent := wikipages.WikiEditContent(page, "Content cannot be empty.", nil, false)
 		component := components.Page("Editing: "+page.Name, editContent)
 		component.Render(context.Background(), c.Writer)
 		return
@@ -105,6 +114,21 @@ func PostEditPage(c *gin.Context) {
 		return
 	}
 
+	// The revision the user started editing from, so the wiki service can
+	// merge in anything saved since then
+	baseRevision := c.PostForm("base_revision")
+	// kept through a conflict so the resolved save has them too
+	summary := c.PostForm("summary")
+	minor := c.PostForm("minor") == "true"
+	if strings.Contains(content, conflictStartMarker) && strings.Contains(content, conflictEndMarker) {
+		conflict := utils.EditConflict{Merged: content}
+		conflict.CurrentRevision, _ = uuid.Parse(baseRevision)
+		conflictContent := wikipages.WikiConflictContent(page, conflict, "Resolve the marked conflicts before saving.", summary, minor)
+		component := components.Page("Edit conflict: "+page.Name, conflictContent)
+		component.Render(context.Background(), c.Writer)
+		return
+	}
+
 	// Step 3 — build multipart form for the API layer
 	//
 	// API: POST /v1/wiki/pages/:id/revisions
@@ -113,6 +137,9 @@ func PostEditPage(c *gin.Context) {
 	//   author      — user's email
 	//   slug        — the page slug
 	//   name        — the page name
+	//   base_revision — the revision the edit started from
+	//   summary     — what the edit changed
+	//   minor       — "true" for a minor edit
 	//   new_content — the markdown content as a file upload
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
@@ -121,6 +148,9 @@ func PostEditPage(c *gin.Context) {
 	writer.WriteField("author", authorEmail)
 	writer.WriteField("slug", page.Slug)
 	writer.WriteField("name", page.Name)
+	writer.WriteField("base_revision", baseRevision)
+	writer.WriteField("summary", summary)
+	writer.WriteField("minor", strconv.FormatBool(minor))
 
 	filePart, err := writer.CreateFormFile("new_content", "content.md")
 	if err != nil {
@@ -150,22 +180,51 @@ func PostEditPage(c *gin.Context) {
 	resp, err := wikiClient.Do(req)
 	if err != nil {
 		// Network error — re-render form with error
-		editContent := wikipages.WikiEditContent(page, "Unable to save changes. The wiki service is unreachable.")
+		editContent := wikipages.WikiEditContent(page, "Unable to save changes. The wiki service is unreachable.", nil, false)
 		component := components.Page("Editing: "+page.Name, editContent)
 		component.Render(context.Background(), c.Writer)
 		return
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict {
+		var conflict utils.EditConflict
+		respBody, err := io.ReadAll(resp.Body)
+		if err == nil && json.Unmarshal(respBody, &conflict) == nil && len(conflict.Conflicts) > 0 {
+			conflictContent := wikipages.WikiConflictContent(page, conflict, "", summary, minor)
+			component := components.Page("Edit conflict: "+page.Name, conflictContent)
+			component.Render(context.Background(), c.Writer)
+			return
+		}
+	}
+
+	// Held for review by a moderator; the draft is kept by the review queue now
+	if resp.StatusCode == http.StatusAccepted {
+		discardDraft(c, id)
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?pending=true", id))
+		return
+	}
+
+	// The page is protected against this user
+	if resp.StatusCode == http.StatusForbidden {
+		editContent := wikipages.WikiEditContent(page, protectionMessage(page.Protection), nil, false)
+		component := components.Page("Editing: "+page.Name, editContent)
+		component.Render(context.Background(), c.Writer)
+		return
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		errMsg := fmt.Sprintf("Unable to save changes. (status %d)", resp.StatusCode)
-		editContent := wikipages.WikiEditContent(page, errMsg)
+		editContent := wikipages.WikiEditContent(page, errMsg, nil, false)
 		component := components.Page("Editing: "+page.Name, editContent)
 		component.Render(context.Background(), c.Writer)
 		return
 	}
 
-	// Step 5 — success, redirect back to the page
+	// Step 5 — success, the draft is saved now
+	discardDraft(c, id)
+
+	// Step 6 — redirect back to the page
 	c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?saved=true", id))
 }
 
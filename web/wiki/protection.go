@@ -0,0 +1,70 @@
+package wiki
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"web/config"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostPageProtection sets who can edit a page from the moderator's
+// protection form on the page
+func PostPageProtection(c *gin.Context) {
+	id := c.Param("id")
+
+	form := url.Values{}
+	form.Set("level", c.PostForm("level"))
+	form.Set("min_account_days", c.PostForm("min_account_days"))
+	form.Set("expires_at", c.PostForm("expires_at"))
+	form.Set("reason", strings.TrimSpace(c.PostForm("reason")))
+
+	req, err := http.NewRequestWithContext(
+		c.Request.Context(),
+		http.MethodPost,
+		fmt.Sprintf("%s/pages/%s/protection", config.WikiURL, id),
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	tokenCookie, err := c.Cookie(authCookieName)
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?error=protection", id))
+		return
+	}
+	c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s", id))
+}
+
+// protectionMessage explains to a user who was refused an edit who can
+// edit the page
+func protectionMessage(p *utils.Protection) string {
+	if p == nil {
+		return "This page is protected, so you can't edit it."
+	}
+	switch p.Level {
+	case "locked":
+		return "This page is locked, so nobody can edit it right now."
+	case "moderator":
+		return "Only moderators can edit this page."
+	default:
+		return fmt.Sprintf("Only accounts at least %d days old can edit this page. If your account is old enough, log out and back in.", p.MinAccountDays)
+	}
+}
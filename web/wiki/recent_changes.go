@@ -0,0 +1,103 @@
+package wiki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"web/config"
+	"web/templates/components"
+	wikipages "web/templates/wiki-pages"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// changeFilters are the query parameters passed on to the recent changes endpoint
+var changeFilters = []string{"since", "until", "category", "namespace", "author", "minor", "type"}
+
+// GetRecentChanges renders the site-wide list of recent changes. HTMX
+// requests for the next page get only the rows.
+func GetRecentChanges(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+
+	filters := url.Values{}
+	for _, name := range changeFilters {
+		if value := c.Query(name); value != "" {
+			filters.Set(name, value)
+		}
+	}
+	// the form's type checkboxes each send their own value
+	if types := c.QueryArray("type"); len(types) > 1 {
+		filters.Set("type", strings.Join(types, ","))
+	}
+
+	changes, err := fetchRecentChanges(filters, c.Query("cursor"))
+	var rejected *rejectedFilter
+	errMsg := ""
+	if errors.As(err, &rejected) {
+		errMsg = rejected.message
+	} else if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		wikipages.WikiRecentChangesItems(changes, filters.Encode()).Render(context.Background(), c.Writer)
+		return
+	}
+
+	content := wikipages.WikiRecentChangesContent(changes, filters, errMsg)
+	components.Page("Recent changes", content).Render(context.Background(), c.Writer)
+}
+
+// rejectedFilter is a filter the wiki refused, with its message. status is
+// 404 for a page or category that doesn't exist.
+type rejectedFilter struct {
+	status  int
+	message string
+}
+
+func (e *rejectedFilter) Error() string {
+	return e.message
+}
+
+// fetchRecentChanges gets a page of recent changes from the API. A filter the
+// wiki rejects is returned as a *rejectedFilter, to show to the user.
+func fetchRecentChanges(filters url.Values, cursor string) (utils.RecentChanges, error) {
+	query := url.Values{}
+	for name, values := range filters {
+		query[name] = values
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	query.Set("count", "50")
+
+	resp, err := http.Get(fmt.Sprintf("%s/recent-changes?%s", config.WikiURL, query.Encode()))
+	if err != nil {
+		return utils.RecentChanges{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return utils.RecentChanges{}, &rejectedFilter{resp.StatusCode, body.Error}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return utils.RecentChanges{}, fmt.Errorf("failed to fetch recent changes: %d", resp.StatusCode)
+	}
+
+	var changes utils.RecentChanges
+	err = json.NewDecoder(resp.Body).Decode(&changes)
+	if err != nil {
+		return utils.RecentChanges{}, err
+	}
+	return changes, nil
+}
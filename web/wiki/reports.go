@@ -0,0 +1,81 @@
+package wiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"web/config"
+	"web/templates/components"
+	wikipages "web/templates/wiki-pages"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reports are the maintenance reports, in the order of their tabs
+var reports = []utils.Report{
+	{Name: "wanted-pages", Title: "Wanted pages"},
+	{Name: "deleted-links", Title: "Links to deleted pages"},
+	{Name: "orphan-pages", Title: "Orphan pages"},
+	{Name: "uncategorized-pages", Title: "Uncategorized pages"},
+}
+
+// GetReports renders the moderator page for one maintenance report
+func GetReports(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+
+	current := reports[0]
+	for _, report := range reports {
+		if report.Name == c.Query("report") {
+			current = report
+		}
+	}
+
+	req, err := http.NewRequestWithContext(
+		c.Request.Context(),
+		http.MethodGet,
+		fmt.Sprintf("%s/reports/%s?index=0&count=200", config.WikiURL, current.Name),
+		nil,
+	)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	tokenCookie, err := c.Cookie("auth_token")
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.AbortWithError(resp.StatusCode, fmt.Errorf("failed to fetch report %s", current.Name))
+		return
+	}
+
+	var wanted []utils.WantedPage
+	var deletedLinks []utils.DeletedLink
+	var pages []utils.PageLink
+	switch current.Name {
+	case "wanted-pages":
+		err = json.NewDecoder(resp.Body).Decode(&wanted)
+	case "deleted-links":
+		err = json.NewDecoder(resp.Body).Decode(&deletedLinks)
+	default:
+		err = json.NewDecoder(resp.Body).Decode(&pages)
+	}
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	content := wikipages.WikiReportsContent(reports, current, wanted, deletedLinks, pages)
+	components.Page(current.Title, content).Render(context.Background(), c.Writer)
+}
@@ -0,0 +1,78 @@
+package wiki
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"web/auth"
+	"web/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PostRevertRevision handles the "restore this version" action from the history view
+func PostRevertRevision(c *gin.Context) {
+	id := c.Param("id")
+	revId := c.Param("revId")
+
+	// Get user email from context (set by RequireRole middleware)
+	userValue, exists := c.Get("user")
+	if !exists {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	user, ok := userValue.(*auth.User)
+	if !ok {
+		c.Redirect(http.StatusFound, "/")
+		return
+	}
+
+	formData := url.Values{}
+	formData.Set("author", user.Email)
+
+	// Forward to API layer
+	req, err := http.NewRequestWithContext(
+		c.Request.Context(),
+		http.MethodPost,
+		fmt.Sprintf("%s/pages/%s/revisions/%s/revert", config.WikiURL, id, revId),
+		bytes.NewBufferString(formData.Encode()),
+	)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	tokenCookie, err := c.Cookie(authCookieName)
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?saved=true", id))
+		return
+	}
+
+	// Held for review by a moderator, like any other edit
+	if resp.StatusCode == http.StatusAccepted {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s?pending=true", id))
+		return
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s/history/%s?error=protected", id, revId))
+		return
+	}
+
+	c.Redirect(http.StatusFound, fmt.Sprintf("/pages/%s/history/%s?error=revert_failed", id, revId))
+}
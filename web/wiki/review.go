@@ -0,0 +1,165 @@
+package wiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"web/config"
+	"web/templates/components"
+	wikipages "web/templates/wiki-pages"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reviewErrors are the messages shown on a held edit after a failed review
+var reviewErrors = map[string]string{
+	"conflict":       "This edit conflicts with changes made to the page since it was submitted. Reject it and ask the author to make it again.",
+	"reviewed":       "Someone else has already reviewed this edit.",
+	"reason":         "Give a reason (up to 500 characters) when rejecting an edit.",
+	"approve_failed": "The edit could not be approved.",
+	"reject_failed":  "The edit could not be rejected.",
+	"protected":      "This page is locked, so the edit can't be applied until its protection is changed.",
+}
+
+// reviewRequest builds a request to the API layer's moderation routes as
+// the logged-in moderator.
+func reviewRequest(c *gin.Context, method string, path string, form url.Values) (*http.Request, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, config.ModerationURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	tokenCookie, err := c.Cookie(authCookieName)
+	if err == nil && tokenCookie != "" {
+		req.Header.Set("Authorization", "Bearer "+tokenCookie)
+	}
+	return req, nil
+}
+
+// fetchReview gets path from the moderation routes and decodes it into v.
+func fetchReview(c *gin.Context, path string, v any) error {
+	req, err := reviewRequest(c, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching %s: status %d", path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// GetReviewQueue renders the moderator list of edits waiting for review
+func GetReviewQueue(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+
+	var items []utils.PendingRevision
+	if err := fetchReview(c, "/pending?index=0&count=100", &items); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	content := wikipages.WikiReviewQueueContent(items, c.Query("done"))
+	components.Page("Review Queue", content).Render(context.Background(), c.Writer)
+}
+
+// GetReviewItem renders a held edit's diff with the approve and reject forms
+func GetReviewItem(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+	id := c.Param("id")
+
+	var item utils.PendingRevision
+	if err := fetchReview(c, fmt.Sprintf("/pending/%s", id), &item); err != nil {
+		c.AbortWithError(http.StatusNotFound, err)
+		return
+	}
+
+	// the page may have been deleted since, in which case there's nothing
+	// to diff against but the edit can still be rejected
+	var diff *utils.PendingDiff
+	var pendingDiff utils.PendingDiff
+	if err := fetchReview(c, fmt.Sprintf("/pending/%s/diff", id), &pendingDiff); err == nil {
+		diff = &pendingDiff
+	}
+
+	content := wikipages.WikiReviewItemContent(item, diff, reviewErrors[c.Query("error")])
+	components.Page("Review: "+item.Name, content).Render(context.Background(), c.Writer)
+}
+
+// PostApproveReview applies a held edit to the wiki
+func PostApproveReview(c *gin.Context) {
+	id := c.Param("id")
+	req, err := reviewRequest(c, http.MethodPost, fmt.Sprintf("/pending/%s/approve", id), url.Values{})
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.Redirect(http.StatusFound, "/review?done=approved")
+	case http.StatusConflict:
+		// either the wiki couldn't merge the edit, or it was already reviewed
+		var conflict utils.EditConflict
+		if json.NewDecoder(resp.Body).Decode(&conflict) == nil && len(conflict.Conflicts) > 0 {
+			c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=conflict", id))
+			return
+		}
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=reviewed", id))
+	case http.StatusForbidden:
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=protected", id))
+	default:
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=approve_failed", id))
+	}
+}
+
+// PostRejectReview rejects a held edit with the moderator's reason
+func PostRejectReview(c *gin.Context) {
+	id := c.Param("id")
+	form := url.Values{}
+	form.Set("reason", strings.TrimSpace(c.PostForm("reason")))
+	req, err := reviewRequest(c, http.MethodPost, fmt.Sprintf("/pending/%s/reject", id), form)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		c.Redirect(http.StatusFound, "/review?done=rejected")
+	case http.StatusBadRequest:
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=reason", id))
+	case http.StatusConflict:
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=reviewed", id))
+	default:
+		c.Redirect(http.StatusFound, fmt.Sprintf("/review/%s?error=reject_failed", id))
+	}
+}
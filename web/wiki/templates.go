@@ -0,0 +1,41 @@
+package wiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"web/config"
+	"web/utils"
+)
+
+// pageTemplates looks templates up in the wiki, for filling in the ones a
+// page includes before it's rendered. Missing and deleted templates aren't
+// found.
+func pageTemplates(ctx context.Context) utils.TemplateLookup {
+	return func(slug string) (string, bool, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/pages/%s", config.WikiURL, url.PathEscape(slug)), nil)
+		if err != nil {
+			return "", false, err
+		}
+		resp, err := wikiClient.Do(req)
+		if err != nil {
+			return "", false, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", false, fmt.Errorf("fetching template %s: status %d", slug, resp.StatusCode)
+		}
+
+		var template utils.Page
+		if err := json.NewDecoder(resp.Body).Decode(&template); err != nil {
+			return "", false, err
+		}
+		return template.Content, true, nil
+	}
+}
@@ -0,0 +1,252 @@
+package wiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"web/templates/components"
+	wikipages "web/templates/wiki-pages"
+	"web/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// notificationsPerPage is how many notifications the inbox loads at a time
+const notificationsPerPage = 20
+
+// fetchWatches lists what the logged-in user watches.
+func fetchWatches(c *gin.Context) ([]utils.Watch, error) {
+	req, err := userRequest(c, http.MethodGet, "/watches", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching watches: status %d", resp.StatusCode)
+	}
+
+	var watches []utils.Watch
+	if err := json.NewDecoder(resp.Body).Decode(&watches); err != nil {
+		return nil, err
+	}
+	return watches, nil
+}
+
+// pageWatchTarget is the watch button for a page, or nil for guests.
+func pageWatchTarget(c *gin.Context, page utils.Page) *utils.WatchTarget {
+	watches, err := fetchWatches(c)
+	if err != nil {
+		return nil
+	}
+	target := &utils.WatchTarget{Field: "page", Value: page.UUID.String()}
+	for i, w := range watches {
+		if w.PageId != nil && *w.PageId == page.UUID {
+			target.Watch = &watches[i]
+			break
+		}
+	}
+	return target
+}
+
+// categoryWatchTarget is the watch button for a category, or nil for guests
+// and the all pages listing.
+func categoryWatchTarget(c *gin.Context, fullSlug string) *utils.WatchTarget {
+	if fullSlug == "" {
+		return nil
+	}
+	watches, err := fetchWatches(c)
+	if err != nil {
+		return nil
+	}
+	target := &utils.WatchTarget{Field: "category", Value: fullSlug}
+	for i, w := range watches {
+		if w.CategorySlug != nil && *w.CategorySlug == fullSlug {
+			target.Watch = &watches[i]
+			break
+		}
+	}
+	return target
+}
+
+// watchRedirect is where to go after a watch form, kept to local paths.
+func watchRedirect(c *gin.Context) string {
+	redirect := c.PostForm("redirect")
+	if redirect == "" || !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") {
+		return "/notifications"
+	}
+	return redirect
+}
+
+// PostWatch starts watching a page or category.
+func PostWatch(c *gin.Context) {
+	form := url.Values{}
+	if page := c.PostForm("page"); page != "" {
+		form.Set("page", page)
+	}
+	if category := c.PostForm("category"); category != "" {
+		form.Set("category", category)
+	}
+
+	req, err := userRequest(c, http.MethodPost, "/watches", form)
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, err)
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.AbortWithError(resp.StatusCode, fmt.Errorf("adding watch: status %d", resp.StatusCode))
+		return
+	}
+	c.Redirect(http.StatusFound, watchRedirect(c))
+}
+
+// PostUnwatch stops watching a page or category.
+func PostUnwatch(c *gin.Context) {
+	req, err := userRequest(c, http.MethodPost, fmt.Sprintf("/watches/%s/delete", c.Param("id")), nil)
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, err)
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	// already gone is as good as removed
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		c.AbortWithError(resp.StatusCode, fmt.Errorf("removing watch: status %d", resp.StatusCode))
+		return
+	}
+	c.Redirect(http.StatusFound, watchRedirect(c))
+}
+
+// fetchInbox gets a page of the user's notifications.
+func fetchInbox(c *gin.Context, index int) (utils.Inbox, error) {
+	query := url.Values{}
+	query.Set("index", strconv.Itoa(index))
+	query.Set("count", strconv.Itoa(notificationsPerPage))
+	req, err := userRequest(c, http.MethodGet, "/notifications?"+query.Encode(), nil)
+	if err != nil {
+		return utils.Inbox{}, err
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		return utils.Inbox{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return utils.Inbox{}, fmt.Errorf("fetching notifications: status %d", resp.StatusCode)
+	}
+
+	var inbox utils.Inbox
+	if err := json.NewDecoder(resp.Body).Decode(&inbox); err != nil {
+		return utils.Inbox{}, err
+	}
+	return inbox, nil
+}
+
+// GetNotifications renders the user's notification inbox and watchlist.
+// HTMX requests for the next page get only the rows.
+func GetNotifications(c *gin.Context) {
+	c.Header("Content-Type", "text/html")
+
+	index, err := strconv.Atoi(c.DefaultQuery("index", "0"))
+	if err != nil || index < 0 {
+		index = 0
+	}
+
+	inbox, err := fetchInbox(c, index)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+
+	if c.GetHeader("HX-Request") == "true" {
+		wikipages.WikiNotificationItems(inbox.Notifications, index, notificationsPerPage).Render(context.Background(), c.Writer)
+		return
+	}
+
+	watches, err := fetchWatches(c)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+
+	content := wikipages.WikiNotificationsContent(inbox, watches, notificationsPerPage)
+	components.Page("Notifications", content).Render(context.Background(), c.Writer)
+}
+
+// GetUnreadCount answers the nav's script with how many notifications the
+// user hasn't read.
+func GetUnreadCount(c *gin.Context) {
+	req, err := userRequest(c, http.MethodGet, "/notifications/unread-count", nil)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "You must be logged in."})
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "The wiki service is unreachable."})
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.JSON(resp.StatusCode, gin.H{"error": "Couldn't count notifications."})
+		return
+	}
+
+	var count struct {
+		Unread int `json:"unread"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&count); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Couldn't read the unread count."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"unread": count.Unread})
+}
+
+// PostMarkRead marks the posted notifications read, or all of them if none
+// are posted.
+func PostMarkRead(c *gin.Context) {
+	form := url.Values{}
+	for _, id := range c.PostFormArray("id") {
+		form.Add("id", id)
+	}
+
+	req, err := userRequest(c, http.MethodPost, "/notifications/read", form)
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, err)
+		return
+	}
+	resp, err := wikiClient.Do(req)
+	if err != nil {
+		c.AbortWithError(http.StatusBadGateway, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		c.AbortWithError(resp.StatusCode, fmt.Errorf("marking notifications read: status %d", resp.StatusCode))
+		return
+	}
+	c.Redirect(http.StatusFound, watchRedirect(c))
+}
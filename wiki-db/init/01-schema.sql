@@ -18,6 +18,9 @@ CREATE TABLE revisions (
     name                TEXT NOT NULL,
     archive_date        DATE,
     deleted_at          TIMESTAMP,
+    reverted_from       UUID REFERENCES revisions(uuid) ON DELETE SET NULL,
+    summary             TEXT NOT NULL DEFAULT '',
+    minor               BOOLEAN NOT NULL DEFAULT false,
     CONSTRAINT uq_page_timestamp UNIQUE (page_id, date_time)
 );
 
@@ -90,3 +93,111 @@ CREATE TABLE page_categories (
     PRIMARY KEY (page_id, category)
 );
 
+-- Old slugs of renamed pages, and aliases added by moderators
+CREATE TABLE slug_redirects (
+    slug                TEXT PRIMARY KEY,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    alias               BOOLEAN NOT NULL DEFAULT false,
+    created_by          TEXT,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX idx_slug_redirects_page ON slug_redirects(page_id);
+
+CREATE OR REPLACE FUNCTION record_slug_redirect()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- a slug a page uses can't redirect anywhere else
+    DELETE FROM slug_redirects WHERE slug = NEW.slug;
+    IF TG_OP = 'UPDATE' AND OLD.slug <> NEW.slug THEN
+        INSERT INTO slug_redirects (slug, page_id) VALUES (OLD.slug, NEW.uuid)
+        ON CONFLICT (slug) DO UPDATE
+        SET page_id = EXCLUDED.page_id, alias = false, created_by = NULL, created_at = now();
+    END IF;
+    RETURN NEW;
+END; $$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_record_slug_redirect
+AFTER INSERT OR UPDATE OF slug ON pages
+FOR EACH ROW EXECUTE FUNCTION record_slug_redirect();
+
+-- The slugs each page links to, kept as slugs so links to missing pages count
+CREATE TABLE page_links (
+    source_id           UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    target_slug         TEXT NOT NULL,
+    PRIMARY KEY (source_id, target_slug)
+);
+
+CREATE INDEX idx_page_links_target ON page_links(target_slug);
+
+-- The templates each page includes with {{...}}, by slug
+CREATE TABLE page_templates (
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    template_slug       TEXT NOT NULL,
+    PRIMARY KEY (page_id, template_slug)
+);
+
+CREATE INDEX idx_page_templates_template ON page_templates(template_slug);
+
+-- Pages to index again because a template they include changed
+CREATE TABLE reindex_queue (
+    page_id             UUID PRIMARY KEY REFERENCES pages(uuid) ON DELETE CASCADE,
+    marked_at           TIMESTAMP DEFAULT now() NOT NULL
+);
+
+-- Unsaved edits, one per user and page, seen only by their author
+CREATE TABLE drafts (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    author              TEXT NOT NULL,
+    base_revision       UUID REFERENCES revisions(uuid) ON DELETE SET NULL,
+    content             TEXT NOT NULL,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    updated_at          TIMESTAMP DEFAULT now() NOT NULL,
+    UNIQUE (author, page_id)
+);
+
+-- Who can edit a page. Pages without a row, or whose protection has
+-- expired, are open to all contributors.
+CREATE TABLE page_protection (
+    page_id             UUID PRIMARY KEY REFERENCES pages(uuid) ON DELETE CASCADE,
+    level               TEXT NOT NULL CHECK (level IN ('semi', 'moderator', 'locked')),
+    min_account_days    INTEGER NOT NULL DEFAULT 0 CHECK (min_account_days >= 0),
+    expires_at          TIMESTAMP,
+    protected_by        TEXT NOT NULL,
+    reason              TEXT NOT NULL DEFAULT '',
+    protected_at        TIMESTAMP DEFAULT now() NOT NULL
+);
+
+-- Pages and category subtrees a user follows. The email is kept so a
+-- user's own changes don't notify them.
+CREATE TABLE watches (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id             UUID NOT NULL,
+    user_email          TEXT NOT NULL,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE,
+    category_id         INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    CONSTRAINT chk_watch_target CHECK ((page_id IS NULL) <> (category_id IS NULL))
+);
+
+CREATE UNIQUE INDEX uq_watches_page ON watches(user_id, page_id) WHERE page_id IS NOT NULL;
+CREATE UNIQUE INDEX uq_watches_category ON watches(user_id, category_id) WHERE category_id IS NOT NULL;
+CREATE INDEX idx_watches_page ON watches(page_id);
+CREATE INDEX idx_watches_category ON watches(category_id);
+
+-- A change to something a user watches, until they've read it
+CREATE TABLE notifications (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id             UUID NOT NULL,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    revision_id         UUID REFERENCES revisions(uuid) ON DELETE CASCADE,
+    kind                TEXT NOT NULL CHECK (kind IN ('edit', 'delete', 'categories')),
+    actor               TEXT NOT NULL,
+    summary             TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    read_at             TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
@@ -0,0 +1,15 @@
+-- Migration: Track reverts on revisions
+-- Adds revisions.reverted_from, pointing at the revision a revert restored
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+                   WHERE table_name = 'revisions' AND column_name = 'reverted_from') THEN
+        ALTER TABLE revisions ADD COLUMN reverted_from UUID REFERENCES revisions(uuid) ON DELETE SET NULL;
+    END IF;
+END $$;
+
+COMMIT;
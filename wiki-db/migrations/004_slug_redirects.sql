@@ -0,0 +1,44 @@
+-- Migration: Redirect old slugs to renamed pages
+-- Adds slug_redirects, filled by a trigger on pages whenever a slug changes,
+-- and backfills it from the slugs recorded on revisions
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS slug_redirects (
+    slug                TEXT PRIMARY KEY,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    alias               BOOLEAN NOT NULL DEFAULT false,
+    created_by          TEXT,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_slug_redirects_page ON slug_redirects(page_id);
+
+CREATE OR REPLACE FUNCTION record_slug_redirect()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- a slug a page uses can't redirect anywhere else
+    DELETE FROM slug_redirects WHERE slug = NEW.slug;
+    IF TG_OP = 'UPDATE' AND OLD.slug <> NEW.slug THEN
+        INSERT INTO slug_redirects (slug, page_id) VALUES (OLD.slug, NEW.uuid)
+        ON CONFLICT (slug) DO UPDATE
+        SET page_id = EXCLUDED.page_id, alias = false, created_by = NULL, created_at = now();
+    END IF;
+    RETURN NEW;
+END; $$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_record_slug_redirect ON pages;
+CREATE TRIGGER trg_record_slug_redirect
+AFTER INSERT OR UPDATE OF slug ON pages
+FOR EACH ROW EXECUTE FUNCTION record_slug_redirect();
+
+-- An old slug goes to the page that used it last
+INSERT INTO slug_redirects (slug, page_id, created_at)
+SELECT DISTINCT ON (r.slug) r.slug, r.page_id, r.date_time
+FROM revisions r
+WHERE NOT EXISTS (SELECT 1 FROM pages p WHERE p.slug = r.slug)
+ORDER BY r.slug, r.date_time DESC
+ON CONFLICT (slug) DO NOTHING;
+
+COMMIT;
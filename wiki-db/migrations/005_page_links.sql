@@ -0,0 +1,17 @@
+-- Migration: Track links between pages
+-- Adds page_links, the slugs each page's content links to. Targets are kept
+-- as slugs so links to pages that don't exist yet are tracked too.
+-- Fill it for existing pages with `go run ./cmd relink` in the wiki service
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS page_links (
+    source_id           UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    target_slug         TEXT NOT NULL,
+    PRIMARY KEY (source_id, target_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_links_target ON page_links(target_slug);
+
+COMMIT;
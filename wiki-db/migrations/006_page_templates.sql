@@ -0,0 +1,23 @@
+-- Migration: Track template includes
+-- Adds page_templates, the templates each page includes with {{...}}, and
+-- reindex_queue, the pages to index again because a template they include
+-- changed. The search service works through the queue.
+-- Fill page_templates for existing pages with `go run ./cmd relink` in the wiki service
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS page_templates (
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    template_slug       TEXT NOT NULL,
+    PRIMARY KEY (page_id, template_slug)
+);
+
+CREATE INDEX IF NOT EXISTS idx_page_templates_template ON page_templates(template_slug);
+
+CREATE TABLE IF NOT EXISTS reindex_queue (
+    page_id             UUID PRIMARY KEY REFERENCES pages(uuid) ON DELETE CASCADE,
+    marked_at           TIMESTAMP DEFAULT now() NOT NULL
+);
+
+COMMIT;
@@ -0,0 +1,19 @@
+-- Migration: Per-user drafts
+-- Adds drafts, the unsaved edits contributors are working on, one per user
+-- and page. Drafts aren't revisions and only their author can see them.
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS drafts (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    author              TEXT NOT NULL,
+    base_revision       UUID REFERENCES revisions(uuid) ON DELETE SET NULL,
+    content             TEXT NOT NULL,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    updated_at          TIMESTAMP DEFAULT now() NOT NULL,
+    UNIQUE (author, page_id)
+);
+
+COMMIT;
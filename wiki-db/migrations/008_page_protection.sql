@@ -0,0 +1,27 @@
+-- Migration: Page protection
+-- Adds page_protection, which limits who can edit a page: 'semi' needs an
+-- account at least min_account_days old, 'moderator' needs a moderator or
+-- admin and 'locked' lets nobody edit. Pages without a row, or whose
+-- protection has expired, are open to all contributors.
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS page_protection (
+    page_id             UUID PRIMARY KEY REFERENCES pages(uuid) ON DELETE CASCADE,
+    level               TEXT NOT NULL CHECK (level IN ('semi', 'moderator', 'locked')),
+    min_account_days    INTEGER NOT NULL DEFAULT 0 CHECK (min_account_days >= 0),
+    expires_at          TIMESTAMP,
+    protected_by        TEXT NOT NULL,
+    reason              TEXT NOT NULL DEFAULT '',
+    protected_at        TIMESTAMP DEFAULT now() NOT NULL
+);
+
+-- Official statements only moderators should change
+INSERT INTO page_protection (page_id, level, protected_by, reason)
+SELECT uuid, 'moderator', 'migration', 'Official university statement'
+FROM pages
+WHERE slug IN ('nazarene-beliefs', 'accreditation', 'mission-and-goals')
+ON CONFLICT (page_id) DO NOTHING;
+
+COMMIT;
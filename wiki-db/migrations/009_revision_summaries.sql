@@ -0,0 +1,42 @@
+-- Migration: Edit summaries and minor edits
+-- Adds revisions.summary and revisions.minor, and gives the revisions the
+-- wiki made itself (creations, deletions, restores and reverts) the
+-- summaries it now writes for them
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+                   WHERE table_name = 'revisions' AND column_name = 'summary') THEN
+        ALTER TABLE revisions ADD COLUMN summary TEXT NOT NULL DEFAULT '';
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM information_schema.columns 
+                   WHERE table_name = 'revisions' AND column_name = 'minor') THEN
+        ALTER TABLE revisions ADD COLUMN minor BOOLEAN NOT NULL DEFAULT false;
+    END IF;
+END $$;
+
+UPDATE revisions SET summary = 'Deleted page'
+WHERE summary = '' AND deleted_at IS NOT NULL;
+
+WITH ordered AS (
+    SELECT uuid,
+           ROW_NUMBER() OVER w AS n,
+           LAG(deleted_at) OVER w AS prev_deleted_at
+    FROM revisions
+    WINDOW w AS (PARTITION BY page_id ORDER BY date_time)
+)
+UPDATE revisions r
+SET summary = CASE WHEN o.n = 1 THEN 'Created page' ELSE 'Restored page' END
+FROM ordered o
+WHERE r.uuid = o.uuid AND r.summary = '' AND r.deleted_at IS NULL
+AND (o.n = 1 OR o.prev_deleted_at IS NOT NULL);
+
+UPDATE revisions r
+SET summary = 'Reverted to the revision by ' || src.author || ' from ' || to_char(src.date_time, 'YYYY-MM-DD HH24:MI')
+FROM revisions src
+WHERE r.reverted_from = src.uuid AND r.summary = '';
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- Migration: Watchlists and notifications
+-- Adds watches, the pages and category subtrees a user (by auth user ID)
+-- follows, and notifications, one per watcher for each revision, deletion
+-- or category change on something they watch. The watcher's email is kept
+-- so their own changes don't notify them.
+-- This migration is idempotent and safe to run multiple times
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS watches (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id             UUID NOT NULL,
+    user_email          TEXT NOT NULL,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE,
+    category_id         INTEGER REFERENCES categories(id) ON DELETE CASCADE,
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    CONSTRAINT chk_watch_target CHECK ((page_id IS NULL) <> (category_id IS NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watches_page ON watches(user_id, page_id) WHERE page_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_watches_category ON watches(user_id, category_id) WHERE category_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_watches_page ON watches(page_id);
+CREATE INDEX IF NOT EXISTS idx_watches_category ON watches(category_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    uuid                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id             UUID NOT NULL,
+    page_id             UUID REFERENCES pages(uuid) ON DELETE CASCADE NOT NULL,
+    revision_id         UUID REFERENCES revisions(uuid) ON DELETE CASCADE,
+    kind                TEXT NOT NULL CHECK (kind IN ('edit', 'delete', 'categories')),
+    actor               TEXT NOT NULL,
+    summary             TEXT NOT NULL DEFAULT '',
+    created_at          TIMESTAMP DEFAULT now() NOT NULL,
+    read_at             TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Rollback: Remove revert tracking from revisions
+-- This reverses migration 003_revision_reverts.sql
+
+BEGIN;
+
+ALTER TABLE revisions DROP COLUMN IF EXISTS reverted_from;
+
+COMMIT;
@@ -0,0 +1,10 @@
+-- Rollback: Remove slug redirects
+-- This reverses migration 004_slug_redirects.sql
+
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_record_slug_redirect ON pages;
+DROP FUNCTION IF EXISTS record_slug_redirect();
+DROP TABLE IF EXISTS slug_redirects;
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Rollback: Remove page links
+-- This reverses migration 005_page_links.sql
+
+BEGIN;
+
+DROP TABLE IF EXISTS page_links;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Rollback: Remove template includes and the reindex queue
+-- This reverses migration 006_page_templates.sql
+
+BEGIN;
+
+DROP TABLE IF EXISTS reindex_queue;
+DROP TABLE IF EXISTS page_templates;
+
+COMMIT;
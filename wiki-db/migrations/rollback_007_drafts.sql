@@ -0,0 +1,8 @@
+-- Rollback: Remove drafts
+-- This reverses migration 007_drafts.sql
+
+BEGIN;
+
+DROP TABLE IF EXISTS drafts;
+
+COMMIT;
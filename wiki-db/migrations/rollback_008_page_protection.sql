@@ -0,0 +1,8 @@
+-- Rollback: Remove page protection
+-- This reverses migration 008_page_protection.sql
+
+BEGIN;
+
+DROP TABLE IF EXISTS page_protection;
+
+COMMIT;
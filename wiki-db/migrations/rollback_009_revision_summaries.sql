@@ -0,0 +1,9 @@
+-- Rollback: Remove edit summaries and minor edits from revisions
+-- This reverses migration 009_revision_summaries.sql
+
+BEGIN;
+
+ALTER TABLE revisions DROP COLUMN IF EXISTS minor;
+ALTER TABLE revisions DROP COLUMN IF EXISTS summary;
+
+COMMIT;
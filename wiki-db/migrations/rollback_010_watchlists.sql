@@ -0,0 +1,9 @@
+-- Rollback: Remove watchlists and notifications
+-- This reverses migration 010_watchlists.sql
+
+BEGIN;
+
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS watches;
+
+COMMIT;
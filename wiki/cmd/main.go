@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"wiki/commands"
 	"wiki/handlers"
 	"wiki/utils"
 
@@ -9,12 +10,32 @@ import (
 )
 
 func main() {
+	// maintenance commands, e.g. `wiki copy-storage -from local -to s3`
+	if len(os.Args) > 1 {
+		os.Exit(commands.Run(os.Args[1:]))
+	}
+
 	// utils import triggers .env loading via init()
 	// Ensure database is accessible
 	_, err := utils.GetDatabase()
 	if err != nil {
 		panic(err)
 	}
+	store, err := utils.GetStorage()
+	if err != nil {
+		panic(err)
+	}
+	handlers.SetStorage(store)
+	snapshotPolicy, err := utils.GetSnapshotPolicy()
+	if err != nil {
+		panic(err)
+	}
+	handlers.SetSnapshotPolicy(snapshotPolicy)
+	revisionCacheBytes, err := utils.GetRevisionCacheBytes()
+	if err != nil {
+		panic(err)
+	}
+	utils.SetRevisionCache(utils.NewRevisionCache(revisionCacheBytes))
 
 	r := gin.Default()
 	r.SetTrustedProxies(nil)
@@ -25,6 +46,7 @@ func main() {
 	// /pages?category={cat}&index={ind}&count={count}
 	r.GET("/pages", handlers.PagesHandler)
 
+	// /pages/{id}
 	r.GET("/pages/:id", handlers.PageHandler)
 
 	r.GET("/revisions", handlers.PageRevisionsHandler)
@@ -33,8 +55,55 @@ func main() {
 
 	r.GET("/pages/:id/revisions/:rev", handlers.PageRevisionHandler)
 
+	// /pages/{id}/diff?from={rev}&to={rev}&format={json|unified}
+	r.GET("/pages/:id/diff", handlers.PageDiffHandler)
+
+	// /pages/{id}/blame?rev={rev}
+	r.GET("/pages/:id/blame", handlers.PageBlameHandler)
+
+	r.GET("/pages/:id/redirects", handlers.PageRedirectsHandler)
+
+	r.GET("/pages/:id/backlinks", handlers.PageBacklinksHandler)
+
+	r.GET("/pages/:id/protection", handlers.PageProtectionHandler)
+
+	r.GET("/protected-pages", handlers.ProtectedPagesHandler)
+
+	// drafts belong to the user named by the X-User-Email header
+	r.GET("/drafts", handlers.DraftsHandler)
+
+	r.GET("/pages/:id/draft", handlers.PageDraftHandler)
+
+	// watches and notifications belong to the user named by the X-User-ID
+	// header
+	r.GET("/watches", handlers.WatchesHandler)
+
+	// /notifications?unread={true}&index={ind}&count={count}
+	r.GET("/notifications", handlers.NotificationsHandler)
+
+	r.GET("/notifications/unread-count", handlers.UnreadCountHandler)
+
 	r.GET("/indexable-pages", handlers.IndexablePagesHandler)
 
+	r.GET("/indexable-pages/:id", handlers.IndexablePageHandler)
+
+	// /reindex-queue?count={count}
+	r.GET("/reindex-queue", handlers.ReindexQueueHandler)
+
+	r.GET("/deleted-pages", handlers.DeletedPagesHandler)
+
+	r.GET("/revision-cache", handlers.RevisionCacheHandler)
+
+	// /reports/{wanted-pages|deleted-links|orphan-pages|uncategorized-pages}?index={ind}&count={count}
+	r.GET("/reports/:report", handlers.ReportHandler)
+
+	// /recent-changes?since={time}&until={time}&category={slug/path}&page={id}&namespace={main|template}
+	//     &author={email}&minor={true|false}&type={create,edit,delete}&cursor={cursor}&count={count}&diff_stats={true}
+	r.GET("/recent-changes", handlers.RecentChangesHandler)
+
+	// /export/git?page={id}
+	r.GET("/export/git", handlers.ExportGitHandler)
+
 	r.GET("/categories", handlers.CategoriesHandler)
 
 	r.GET("/pages/:id/categories", handlers.GetPageCategoriesHandler)
@@ -45,10 +114,33 @@ func main() {
 
 	r.POST("/pages/:id/delete", handlers.DeletePageHandler)
 
+	r.POST("/pages/:id/restore", handlers.RestorePageHandler)
+
 	r.POST("/pages/:id/revisions", handlers.NewRevisionHandler)
 
+	r.POST("/pages/:id/revisions/:rev/revert", handlers.RevertRevisionHandler)
+
 	r.POST("/pages/:id/categories", handlers.SetPageCategoriesHandler) // Requires auth
 
+	r.POST("/pages/:id/redirects", handlers.NewAliasHandler)
+
+	r.POST("/pages/:id/redirects/:slug/delete", handlers.DeleteRedirectHandler)
+
+	r.POST("/pages/:id/protection", handlers.SetPageProtectionHandler)
+
+	r.POST("/reindex-queue/:id/done", handlers.ReindexDoneHandler)
+
+	r.POST("/pages/:id/draft", handlers.SaveDraftHandler)
+
+	r.POST("/pages/:id/draft/delete", handlers.DeleteDraftHandler)
+
+	r.POST("/watches", handlers.AddWatchHandler)
+
+	r.POST("/watches/:id/delete", handlers.DeleteWatchHandler)
+
+	// marks the notifications given as id fields read, or all of them
+	r.POST("/notifications/read", handlers.MarkReadHandler)
+
 	// Use port from environment variable, default to 9454
 	port := os.Getenv("WIKI_SERVICE_PORT")
 	if port == "" {
@@ -0,0 +1,307 @@
+package commands
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// backupVersion is the archive format written by Backup. Restore refuses
+// archives of any other version.
+const backupVersion = 1
+
+const backupManifestName = "manifest.json"
+
+// backupTable is a table held in a backup. Tables are written, and restored,
+// in the order of backupTables, so a table comes after the ones it refers to.
+type backupTable struct {
+	name  string
+	order string
+}
+
+var backupTables = []backupTable{
+	{"categories", "nlevel(path), id"},
+	{"pages", "uuid"},
+	{"watches", "uuid"},
+	{"slug_redirects", "slug"},
+	{"page_links", "source_id, target_slug"},
+	{"page_templates", "page_id, template_slug"},
+	{"revisions", "date_time, uuid"},
+	{"notifications", "created_at, uuid"},
+	{"drafts", "uuid"},
+	{"page_protection", "page_id"},
+	{"snapshots", "uuid"},
+	{"page_categories", "page_id, category"},
+}
+
+// BackupEntry is the size and SHA-256 checksum of one file in the archive.
+type BackupEntry struct {
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// BackupManifest describes a backup archive. It's the last entry of the
+// archive and lists every other entry with its checksum.
+type BackupManifest struct {
+	Version   int                    `json:"version"`
+	CreatedAt time.Time              `json:"created_at"`
+	Tables    map[string]int         `json:"tables"`
+	Files     int                    `json:"files"`
+	Entries   map[string]BackupEntry `json:"entries"`
+}
+
+// Backup writes the wiki's tables and the files of every page, revision and
+// snapshot to w as a tar archive:
+//
+//	db/<table>.jsonl                          one JSON row per line
+//	files/pages/<page>.md
+//	files/revisions/<page>/<revision>.txt
+//	files/snapshots/<page>/<snapshot>.md
+//	manifest.json
+//
+// Everything comes from one point in time: the tables are read in a single
+// repeatable-read transaction holding a SHARE lock on pages, which holds off
+// edits (they update pages) until the backup is done. Files left in storage
+// with no row aren't backed up. A row whose file is missing fails the backup;
+// run fsck to find out why.
+func Backup(ctx context.Context, db *sql.DB, store filesystem.Storage, w io.Writer) (*BackupManifest, error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, `LOCK TABLE pages IN SHARE MODE;`)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BackupManifest{
+		Version:   backupVersion,
+		CreatedAt: time.Now().UTC(),
+		Tables:    make(map[string]int),
+		Entries:   make(map[string]BackupEntry),
+	}
+	tw := tar.NewWriter(w)
+	add := func(name string, data []byte) error {
+		sum := sha256.Sum256(data)
+		manifest.Entries[name] = BackupEntry{Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])}
+		return writeTarEntry(tw, name, data, manifest.CreatedAt)
+	}
+
+	for _, table := range backupTables {
+		var buf bytes.Buffer
+		rows, err := tx.QueryContext(ctx, fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t ORDER BY %s;`, table.name, table.order))
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", table.name, err)
+		}
+		count := 0
+		for rows.Next() {
+			var row string
+			err = rows.Scan(&row)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("reading %s: %w", table.name, err)
+			}
+			buf.WriteString(row)
+			buf.WriteByte('\n')
+			count++
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", table.name, err)
+		}
+		manifest.Tables[table.name] = count
+		err = add("db/"+table.name+".jsonl", buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files, err := backupFileRefs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		var content string
+		switch file.kind {
+		case filesystem.PageFile:
+			content, err = store.GetPage(ctx, file.page)
+		case filesystem.RevisionFile:
+			content, err = store.GetRevision(ctx, file.page, file.id)
+		case filesystem.SnapshotFile:
+			content, err = store.GetSnapshot(ctx, file.page, file.id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s %s of page %s (fsck will report what's missing): %w", file.kind, file.id, file.page, err)
+		}
+		err = add(backupFilePath(file), []byte(content))
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files++
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = writeTarEntry(tw, backupManifestName, data, manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	err = tw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return manifest, tx.Commit()
+}
+
+type backupFileRef struct {
+	kind filesystem.FileKind
+	page uuid.UUID
+	id   uuid.UUID
+}
+
+func backupFileRefs(ctx context.Context, tx *sql.Tx) ([]backupFileRef, error) {
+	var refs []backupFileRef
+	queries := []struct {
+		kind  filesystem.FileKind
+		query string
+	}{
+		{filesystem.PageFile, `SELECT uuid, uuid FROM pages ORDER BY uuid;`},
+		{filesystem.RevisionFile, `SELECT page_id, uuid FROM revisions ORDER BY page_id, uuid;`},
+		{filesystem.SnapshotFile, `SELECT page, uuid FROM snapshots ORDER BY page, uuid;`},
+	}
+	for _, q := range queries {
+		rows, err := tx.QueryContext(ctx, q.query)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			ref := backupFileRef{kind: q.kind}
+			err = rows.Scan(&ref.page, &ref.id)
+			if err != nil {
+				rows.Close()
+				return nil, err
+			}
+			refs = append(refs, ref)
+		}
+		rows.Close()
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+func backupFilePath(ref backupFileRef) string {
+	switch ref.kind {
+	case filesystem.PageFile:
+		return fmt.Sprintf("files/pages/%s.md", ref.page)
+	case filesystem.RevisionFile:
+		return fmt.Sprintf("files/revisions/%s/%s.txt", ref.page, ref.id)
+	default:
+		return fmt.Sprintf("files/snapshots/%s/%s.md", ref.page, ref.id)
+	}
+}
+
+// parseBackupFilePath is the reverse of backupFilePath.
+func parseBackupFilePath(name string) (backupFileRef, bool) {
+	var ref backupFileRef
+	var ext string
+	parts := strings.Split(name, "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "files" && parts[1] == "pages":
+		ref.kind, ext = filesystem.PageFile, ".md"
+	case len(parts) == 4 && parts[0] == "files" && parts[1] == "revisions":
+		ref.kind, ext = filesystem.RevisionFile, ".txt"
+	case len(parts) == 4 && parts[0] == "files" && parts[1] == "snapshots":
+		ref.kind, ext = filesystem.SnapshotFile, ".md"
+	default:
+		return ref, false
+	}
+	base, ok := strings.CutSuffix(parts[len(parts)-1], ext)
+	if !ok {
+		return ref, false
+	}
+	id, err := uuid.Parse(base)
+	if err != nil {
+		return ref, false
+	}
+	ref.id = id
+	ref.page = id
+	if ref.kind != filesystem.PageFile {
+		ref.page, err = uuid.Parse(parts[2])
+		if err != nil {
+			return ref, false
+		}
+	}
+	return ref, true
+}
+
+func writeTarEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func backupCommand(args []string) int {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "", "file to write the archive to (default stdout)")
+	flags.Parse(args)
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	manifest, err := Backup(context.Background(), db, store, w)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	log.Printf("backed up %d pages, %d revisions, %d snapshots and %d files\n",
+		manifest.Tables["pages"], manifest.Tables["revisions"], manifest.Tables["snapshots"], manifest.Files)
+	return 0
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+)
+
+// Run runs the maintenance command named by args[0] and returns the process
+// exit code.
+func Run(args []string) int {
+	switch args[0] {
+	case "backup":
+		return backupCommand(args[1:])
+	case "compact-snapshots":
+		return compactSnapshotsCommand(args[1:])
+	case "copy-storage":
+		return copyStorageCommand(args[1:])
+	case "export-git":
+		return exportGitCommand(args[1:])
+	case "fsck":
+		return fsckCommand(args[1:])
+	case "import":
+		return importCommand(args[1:])
+	case "migrate-layout":
+		return migrateLayoutCommand(args[1:])
+	case "relink":
+		return relinkCommand(args[1:])
+	case "restore":
+		return restoreCommand(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		fmt.Fprintln(os.Stderr, "commands:")
+		fmt.Fprintln(os.Stderr, "  backup [-o <file>]                            write the database and all files to a tar archive")
+		fmt.Fprintln(os.Stderr, "  compact-snapshots [-dry-run]                  add and drop snapshots to match the snapshot policy")
+		fmt.Fprintln(os.Stderr, "  copy-storage -from <backend> -to <backend>   copy all wiki files between storage backends")
+		fmt.Fprintln(os.Stderr, "  export-git [-page <id>] [-o <file>]          write history as a git fast-import stream")
+		fmt.Fprintln(os.Stderr, "  fsck [-repair]                                check storage against the database")
+		fmt.Fprintln(os.Stderr, "  import -mediawiki <file> | -markdown <dir>   import pages with their history from another wiki")
+		fmt.Fprintln(os.Stderr, "  migrate-layout [-dir <path>]                  rename local files from slug-based to ID-based names")
+		fmt.Fprintln(os.Stderr, "  relink                                        rebuild the links between pages from their content")
+		fmt.Fprintln(os.Stderr, "  restore [-check] <file>                       load a backup into an empty database and storage")
+		return 2
+	}
+}
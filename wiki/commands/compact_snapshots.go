@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"wiki/database"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+type CompactReport struct {
+	Pages   int  `json:"pages"`
+	Added   int  `json:"added"`
+	Dropped int  `json:"dropped"`
+	Failed  int  `json:"failed"`
+	DryRun  bool `json:"dry_run"`
+}
+
+// CompactSnapshots brings every page's snapshots in line with the policy:
+// revisions at the end of a diff chain the policy considers too long get a
+// snapshot, and snapshots the policy doesn't need are dropped. New snapshots
+// are written before any are dropped, so history stays rebuildable if the
+// command stops part way. A page that fails is logged and skipped.
+//
+// It trusts that stored history is sound; run fsck first.
+func CompactSnapshots(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, dryRun bool) (*CompactReport, error) {
+	report := &CompactReport{DryRun: dryRun}
+	pageIds, err := queryIds(ctx, db, `SELECT uuid FROM pages ORDER BY slug;`)
+	if err != nil {
+		return nil, err
+	}
+	for _, pageId := range pageIds {
+		report.Pages++
+		added, dropped, err := compactPage(ctx, db, store, policy, pageId, dryRun)
+		report.Added += added
+		report.Dropped += dropped
+		if err != nil {
+			log.Printf("compacting snapshots for page %s: %v", pageId, err)
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+func compactPage(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, pageId uuid.UUID, dryRun bool) (added int, dropped int, err error) {
+	revInfos, err := database.GetPageRevisionsInfo(ctx, db, pageId)
+	if err != nil {
+		return 0, 0, err
+	}
+	snaps, err := database.GetPageSnapshots(ctx, db, pageId)
+	if err != nil {
+		return 0, 0, err
+	}
+	snapsByRev := make(map[uuid.UUID][]uuid.UUID)
+	for _, snap := range snaps {
+		// snapshots not tied to a revision are left alone
+		if snap.Revision != nil {
+			snapsByRev[*snap.Revision] = append(snapsByRev[*snap.Revision], snap.UUID)
+		}
+	}
+
+	revs := make([]utils.SnapshotPlanRevision, len(revInfos))
+	for i, info := range revInfos {
+		revs[i] = utils.SnapshotPlanRevision{
+			ID:        *info.UUID,
+			DateTime:  *info.DateTime,
+			Snapshots: snapsByRev[*info.UUID],
+		}
+		if policy.MaxDiffBytes > 0 {
+			revDiff, err := store.GetRevision(ctx, pageId, *info.UUID)
+			if err != nil {
+				return 0, 0, err
+			}
+			revs[i].DiffBytes = int64(len(revDiff))
+		}
+	}
+
+	add, drop := utils.PlanSnapshots(revs, policy)
+	if dryRun {
+		return len(add), len(drop), nil
+	}
+
+	for _, revId := range add {
+		err = addSnapshot(ctx, db, store, pageId, revId)
+		if err != nil {
+			return added, dropped, fmt.Errorf("adding snapshot at revision %s: %w", revId, err)
+		}
+		added++
+	}
+	for _, snapId := range drop {
+		_, err = db.ExecContext(ctx, `DELETE FROM snapshots WHERE uuid=$1;`, snapId)
+		if err != nil {
+			return added, dropped, fmt.Errorf("dropping snapshot %s: %w", snapId, err)
+		}
+		err = store.DeleteSnapshot(ctx, pageId, snapId)
+		if err != nil {
+			return added, dropped, fmt.Errorf("deleting snapshot file %s: %w", snapId, err)
+		}
+		dropped++
+	}
+	return added, dropped, nil
+}
+
+func addSnapshot(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId, revId uuid.UUID) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	snapId, err := utils.CreateSnapshot(ctx, db, tx, store, pageId, revId)
+	if err != nil {
+		if snapId != uuid.Nil {
+			store.DeleteSnapshot(ctx, pageId, snapId)
+		}
+		return err
+	}
+	err = tx.Commit()
+	if err != nil {
+		store.DeleteSnapshot(ctx, pageId, snapId)
+		return err
+	}
+	return nil
+}
+
+func compactSnapshotsCommand(args []string) int {
+	flags := flag.NewFlagSet("compact-snapshots", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would change without changing it")
+	flags.Parse(args)
+
+	policy, err := utils.GetSnapshotPolicy()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	report, err := CompactSnapshots(context.Background(), db, store, policy, *dryRun)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// CopyReport counts what CopyStorage did.
+type CopyReport struct {
+	Pages     int
+	Revisions int
+	Snapshots int
+	Missing   int
+}
+
+// CopyStorage copies the page, revision and snapshot files of every page in
+// the database (deleted ones included) from one backend to another. Files
+// missing from the source are logged and counted, and don't stop the copy.
+func CopyStorage(ctx context.Context, db *sql.DB, from, to filesystem.Storage) (CopyReport, error) {
+	var report CopyReport
+
+	type pageRef struct {
+		id   uuid.UUID
+		slug string
+	}
+	var pages []pageRef
+	rows, err := db.QueryContext(ctx, `SELECT uuid, slug FROM pages ORDER BY slug;`)
+	if err != nil {
+		return report, err
+	}
+	for rows.Next() {
+		var page pageRef
+		err = rows.Scan(&page.id, &page.slug)
+		if err != nil {
+			rows.Close()
+			return report, err
+		}
+		pages = append(pages, page)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return report, err
+	}
+
+	for _, page := range pages {
+		content, err := from.GetPage(ctx, page.id)
+		if err != nil {
+			log.Printf("page %s (%s): %s\n", page.slug, page.id, err)
+			report.Missing++
+		} else {
+			err = to.PutPage(ctx, page.id, content)
+			if err != nil {
+				return report, fmt.Errorf("writing page %s: %w", page.slug, err)
+			}
+			report.Pages++
+		}
+
+		revIds, err := queryIds(ctx, db, `SELECT uuid FROM revisions WHERE page_id=$1;`, page.id)
+		if err != nil {
+			return report, err
+		}
+		for _, revId := range revIds {
+			content, err := from.GetRevision(ctx, page.id, revId)
+			if err != nil {
+				log.Printf("revision %s of %s: %s\n", revId, page.slug, err)
+				report.Missing++
+				continue
+			}
+			err = to.PutRevision(ctx, page.id, revId, content)
+			if err != nil {
+				return report, fmt.Errorf("writing revision %s: %w", revId, err)
+			}
+			report.Revisions++
+		}
+
+		snapIds, err := queryIds(ctx, db, `SELECT uuid FROM snapshots WHERE page=$1;`, page.id)
+		if err != nil {
+			return report, err
+		}
+		for _, snapId := range snapIds {
+			content, err := from.GetSnapshot(ctx, page.id, snapId)
+			if err != nil {
+				log.Printf("snapshot %s of %s: %s\n", snapId, page.slug, err)
+				report.Missing++
+				continue
+			}
+			err = to.PutSnapshot(ctx, page.id, snapId, content)
+			if err != nil {
+				return report, fmt.Errorf("writing snapshot %s: %w", snapId, err)
+			}
+			report.Snapshots++
+		}
+	}
+
+	return report, nil
+}
+
+func queryIds(ctx context.Context, db *sql.DB, query string, args ...any) ([]uuid.UUID, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func copyStorageCommand(args []string) int {
+	flags := flag.NewFlagSet("copy-storage", flag.ExitOnError)
+	fromName := flags.String("from", "local", "storage backend to copy from")
+	toName := flags.String("to", "", "storage backend to copy to")
+	flags.Parse(args)
+	if *toName == "" || *toName == *fromName {
+		fmt.Println("copy-storage: -to must name a backend other than -from")
+		return 2
+	}
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	from, err := utils.NewStorage(*fromName)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	to, err := utils.NewStorage(*toName)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	report, err := CopyStorage(context.Background(), db, from, to)
+	fmt.Printf("copied %d pages, %d revisions and %d snapshots; %d files missing from %s\n",
+		report.Pages, report.Revisions, report.Snapshots, report.Missing, *fromName)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	if report.Missing > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"wiki/requests"
+	"wiki/utils"
+)
+
+func exportGitCommand(args []string) int {
+	flags := flag.NewFlagSet("export-git", flag.ExitOnError)
+	page := flags.String("page", "", "slug or ID of a single page to export")
+	output := flags.String("o", "", "file to write the stream to (default stdout)")
+	flags.Parse(args)
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	err = requests.ExportHistory(context.Background(), db, store, w, *page)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
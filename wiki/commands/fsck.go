@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"log"
+	"os"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// a database row whose file isn't in storage
+	problemMissingFile = "missing_file"
+	// a revision diff that doesn't parse or apply to the content before it
+	problemDiffFailed = "diff_failed"
+	// a snapshot that differs from the content rebuilt from history
+	problemSnapshotMismatch = "snapshot_mismatch"
+	// a page file that differs from the content rebuilt at last_revision_id
+	problemPageMismatch = "page_mismatch"
+	// a page file that couldn't be checked because history is broken before it
+	problemPageUnverified = "page_unverified"
+	// a file in storage with no database row
+	problemOrphanFile = "orphan_file"
+)
+
+type FsckProblem struct {
+	Kind     string     `json:"kind"`
+	Page     *uuid.UUID `json:"page,omitempty"`
+	Slug     string     `json:"slug,omitempty"`
+	Revision *uuid.UUID `json:"revision,omitempty"`
+	Snapshot *uuid.UUID `json:"snapshot,omitempty"`
+	File     string     `json:"file,omitempty"`
+	Detail   string     `json:"detail,omitempty"`
+	Repaired bool       `json:"repaired"`
+}
+
+type FsckReport struct {
+	Pages     int           `json:"pages"`
+	Revisions int           `json:"revisions"`
+	Snapshots int           `json:"snapshots"`
+	Repair    bool          `json:"repair"`
+	Problems  []FsckProblem `json:"problems"`
+}
+
+// Unrepaired counts the problems that are still there.
+func (r *FsckReport) Unrepaired() int {
+	count := 0
+	for _, p := range r.Problems {
+		if !p.Repaired {
+			count++
+		}
+	}
+	return count
+}
+
+type fsckPage struct {
+	id      uuid.UUID
+	slug    string
+	lastRev *uuid.UUID
+}
+
+// Fsck checks that storage agrees with the database. For every page
+// (deleted ones included) it replays the revision diffs in order, checking
+// that each applies, that each snapshot matches the rebuilt content and that
+// the page file matches the content at last_revision_id. Where a diff is
+// broken, replay picks up again from the next snapshot. Files missing for a
+// database row, and files with no row, are reported too.
+//
+// With repair set, page files and snapshots that are missing or differ from
+// the rebuilt content are rewritten from history. Broken diffs and orphan
+// files are only reported.
+func Fsck(ctx context.Context, db *sql.DB, store filesystem.Storage, repair bool) (*FsckReport, error) {
+	report := &FsckReport{Repair: repair, Problems: []FsckProblem{}}
+	known := map[filesystem.FileKind]map[uuid.UUID]bool{
+		filesystem.PageFile:     {},
+		filesystem.RevisionFile: {},
+		filesystem.SnapshotFile: {},
+	}
+
+	var pages []fsckPage
+	rows, err := db.QueryContext(ctx, `SELECT uuid, slug, last_revision_id FROM pages ORDER BY slug;`)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var page fsckPage
+		err = rows.Scan(&page.id, &page.slug, &page.lastRev)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, page := range pages {
+		report.Pages++
+		known[filesystem.PageFile][page.id] = true
+		pageId := page.id
+		problem := func(kind string) *FsckProblem {
+			report.Problems = append(report.Problems, FsckProblem{Kind: kind, Page: &pageId, Slug: page.slug})
+			return &report.Problems[len(report.Problems)-1]
+		}
+
+		revIds, err := queryIds(ctx, db, `SELECT uuid FROM revisions WHERE page_id=$1 ORDER BY date_time ASC;`, page.id)
+		if err != nil {
+			return nil, err
+		}
+		snapsByRev := make(map[uuid.UUID][]uuid.UUID)
+		snapRows, err := db.QueryContext(ctx, `SELECT uuid, revision FROM snapshots WHERE page=$1;`, page.id)
+		if err != nil {
+			return nil, err
+		}
+		for snapRows.Next() {
+			var snapId uuid.UUID
+			var revId *uuid.UUID
+			err = snapRows.Scan(&snapId, &revId)
+			if err != nil {
+				snapRows.Close()
+				return nil, err
+			}
+			if revId == nil {
+				revId = &uuid.Nil
+			}
+			snapsByRev[*revId] = append(snapsByRev[*revId], snapId)
+			known[filesystem.SnapshotFile][snapId] = true
+		}
+		snapRows.Close()
+
+		// replay history; valid is false while the content can't be rebuilt
+		content, valid := "", true
+		pageContent, pageValid := "", false
+		for _, revId := range revIds {
+			report.Revisions++
+			known[filesystem.RevisionFile][revId] = true
+			revId := revId
+
+			revDiff, err := store.GetRevision(ctx, page.id, revId)
+			switch {
+			case errors.Is(err, fs.ErrNotExist):
+				p := problem(problemMissingFile)
+				p.Revision, p.File = &revId, filesystem.GetRevisionFilename(revId)
+				valid = false
+			case err != nil:
+				return nil, err
+			case valid:
+				content, err = utils.ApplyRevisionDiff(content, revDiff)
+				if err != nil {
+					p := problem(problemDiffFailed)
+					p.Revision, p.Detail = &revId, err.Error()
+					valid = false
+				}
+			}
+
+			for _, snapId := range snapsByRev[revId] {
+				report.Snapshots++
+				snapId := snapId
+				snap, err := store.GetSnapshot(ctx, page.id, snapId)
+				var p *FsckProblem
+				switch {
+				case errors.Is(err, fs.ErrNotExist):
+					p = problem(problemMissingFile)
+					p.File = filesystem.GetSnapshotFilename(snapId)
+				case err != nil:
+					return nil, err
+				case !valid:
+					// resume replay from the snapshot
+					content, valid = snap, true
+				case snap != content:
+					p = problem(problemSnapshotMismatch)
+				}
+				if p == nil {
+					continue
+				}
+				p.Revision, p.Snapshot = &revId, &snapId
+				if repair && valid {
+					err = store.PutSnapshot(ctx, page.id, snapId, content)
+					if err != nil {
+						return nil, err
+					}
+					p.Repaired = true
+				}
+			}
+
+			if page.lastRev != nil && *page.lastRev == revId {
+				pageContent, pageValid = content, valid
+			}
+		}
+
+		// snapshots not tied to a revision can only be checked for existence
+		for _, snapId := range snapsByRev[uuid.Nil] {
+			report.Snapshots++
+			snapId := snapId
+			_, err := store.GetSnapshot(ctx, page.id, snapId)
+			if errors.Is(err, fs.ErrNotExist) {
+				p := problem(problemMissingFile)
+				p.Snapshot, p.File = &snapId, filesystem.GetSnapshotFilename(snapId)
+			} else if err != nil {
+				return nil, err
+			}
+		}
+
+		current, err := store.GetPage(ctx, page.id)
+		var p *FsckProblem
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			p = problem(problemMissingFile)
+			p.File = filesystem.GetPageFilename(page.id)
+		case err != nil:
+			return nil, err
+		case page.lastRev == nil:
+			continue
+		case !pageValid:
+			p = problem(problemPageUnverified)
+			p.Revision, p.Detail = page.lastRev, "content at last_revision_id can't be rebuilt"
+		case current != pageContent:
+			p = problem(problemPageMismatch)
+			p.Revision = page.lastRev
+		}
+		if p != nil && repair && pageValid {
+			err = store.PutPage(ctx, page.id, pageContent)
+			if err != nil {
+				return nil, err
+			}
+			p.Repaired = true
+		}
+	}
+
+	files, err := store.ListFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.ID != uuid.Nil && known[file.Kind][file.ID] {
+			continue
+		}
+		report.Problems = append(report.Problems, FsckProblem{
+			Kind:   problemOrphanFile,
+			File:   file.Name,
+			Detail: string(file.Kind),
+		})
+	}
+
+	return report, nil
+}
+
+func fsckCommand(args []string) int {
+	flags := flag.NewFlagSet("fsck", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "rewrite page files and snapshots from history")
+	flags.Parse(args)
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	report, err := Fsck(context.Background(), db, store, *repair)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if report.Unrepaired() > 0 {
+		return 1
+	}
+	return 0
+}
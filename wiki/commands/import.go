@@ -0,0 +1,251 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"wiki/database"
+	"wiki/filesystem"
+	"wiki/requests"
+	"wiki/utils"
+)
+
+const (
+	// a page whose slug is already taken, here or earlier in the import
+	importSlugCollision = "slug_collision"
+	// a page whose title doesn't make a usable slug
+	importInvalidSlug = "invalid_slug"
+	// a category with no match here, when categories aren't being created
+	importUnknownCategory = "unknown_category"
+	// a page that failed part way; earlier revisions may have been created
+	importFailed = "failed"
+)
+
+type ImportProblem struct {
+	Kind   string `json:"kind"`
+	Source string `json:"source"`
+	Slug   string `json:"slug,omitempty"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type ImportReport struct {
+	Pages     int             `json:"pages"`
+	Revisions int             `json:"revisions"`
+	Skipped   int             `json:"skipped"`
+	DryRun    bool            `json:"dry_run"`
+	Problems  []ImportProblem `json:"problems"`
+}
+
+type ImportOptions struct {
+	// CreateCategories creates categories that don't exist yet instead of
+	// reporting them
+	CreateCategories bool
+	DryRun           bool
+}
+
+// Import creates pages with their full history, through the same code paths
+// as the API: the first revision creates the page with utils.CreateNewPage and
+// each later one is posted like an edit, so diffs, snapshots and page files
+// come out as if the edits had been made here. Authors and timestamps are
+// kept.
+//
+// A page whose slug is taken, already or by an earlier page of the import,
+// is skipped and reported. Categories are matched by slug path for a
+// directory import ("people/faculty") and by slug for MediaWiki categories.
+func Import(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, pages []utils.ImportPage, opts ImportOptions) (*ImportReport, error) {
+	report := &ImportReport{DryRun: opts.DryRun, Problems: []ImportProblem{}}
+	problem := func(kind string, page utils.ImportPage, detail string) {
+		report.Problems = append(report.Problems, ImportProblem{Kind: kind, Source: page.Source, Slug: page.Slug, Detail: detail})
+	}
+
+	claimed := make(map[string]string)
+	for _, page := range pages {
+		if page.Slug == "" {
+			problem(importInvalidSlug, page, "")
+			report.Skipped++
+			continue
+		}
+		if source, ok := claimed[page.Slug]; ok {
+			problem(importSlugCollision, page, "also imported from "+source)
+			report.Skipped++
+			continue
+		}
+		claimed[page.Slug] = page.Source
+
+		var existing string
+		err := db.QueryRowContext(ctx, `SELECT uuid FROM pages WHERE slug=$1;`, page.Slug).Scan(&existing)
+		if err == nil {
+			problem(importSlugCollision, page, "already used by page "+existing)
+			report.Skipped++
+			continue
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+
+		categories, err := importCategories(ctx, db, page, opts)
+		if err != nil {
+			problem(importUnknownCategory, page, err.Error())
+		}
+
+		if opts.DryRun {
+			report.Pages++
+			report.Revisions += len(page.Revisions)
+			continue
+		}
+		revisions, err := importPage(ctx, db, store, policy, page)
+		report.Revisions += revisions
+		if err != nil {
+			problem(importFailed, page, err.Error())
+			report.Skipped++
+			continue
+		}
+		report.Pages++
+
+		if len(categories) > 0 {
+			err = database.SetPageCategories(ctx, db, page.Slug, categories, page.Revisions[len(page.Revisions)-1].Author)
+			if err != nil {
+				problem(importFailed, page, fmt.Sprintf("setting categories: %s", err))
+			}
+		}
+	}
+	return report, nil
+}
+
+// importCategories resolves a page's categories to slug paths. The ones it
+// can't resolve are left out and named in the error.
+func importCategories(ctx context.Context, db *sql.DB, page utils.ImportPage, opts ImportOptions) ([]string, error) {
+	var found []string
+	var missing []error
+	for _, slugPath := range page.Categories {
+		cat, err := database.GetCategoryBySlugPath(ctx, db, slugPath)
+		if err != nil && !strings.Contains(slugPath, "/") {
+			// a MediaWiki category may match a nested one by its own slug
+			cat, err = database.GetCategoryBySlug(ctx, db, slugPath)
+		}
+		if err != nil && opts.CreateCategories {
+			if opts.DryRun {
+				continue
+			}
+			cat, err = database.CreateCategoryPath(ctx, db, slugPath)
+		}
+		if err != nil {
+			missing = append(missing, fmt.Errorf("%s: %w", slugPath, err))
+			continue
+		}
+		found = append(found, cat.FullSlug)
+	}
+	return found, errors.Join(missing...)
+}
+
+// importPage creates the page and posts its later revisions, returning how
+// many revisions were created.
+func importPage(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, page utils.ImportPage) (int, error) {
+	first := page.Revisions[0]
+	err := utils.CreateNewPage(ctx, db, store, utils.NewPageRequest{
+		Slug:     page.Slug,
+		Name:     page.Name,
+		Author:   first.Author,
+		Content:  first.Content,
+		Summary:  first.Summary,
+		DateTime: &first.DateTime,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, rev := range page.Revisions[1:] {
+		err = requests.PostRevision(ctx, db, store, policy, utils.RevisionRequest{
+			PageId:     page.Slug,
+			Author:     rev.Author,
+			Slug:       page.Slug,
+			Name:       page.Name,
+			Summary:    rev.Summary,
+			Minor:      rev.Minor,
+			NewContent: rev.Content,
+			DateTime:   &rev.DateTime,
+		})
+		if err != nil {
+			return i + 1, fmt.Errorf("revision at %s: %w", rev.DateTime, err)
+		}
+	}
+	return len(page.Revisions), nil
+}
+
+func importCommand(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	mediaWiki := flags.String("mediawiki", "", "MediaWiki XML export to import")
+	markdownDir := flags.String("markdown", "", "directory of .md files to import")
+	author := flags.String("author", "", "author of pages imported from -markdown")
+	emailDomain := flags.String("email-domain", "", "domain appended to MediaWiki user names to make author emails")
+	createCategories := flags.Bool("create-categories", false, "create categories that don't exist yet")
+	dryRun := flags.Bool("dry-run", false, "report what would be imported without importing it")
+	flags.Parse(args)
+
+	var pages []utils.ImportPage
+	var err error
+	switch {
+	case *mediaWiki != "" && *markdownDir == "":
+		var f *os.File
+		f, err = os.Open(*mediaWiki)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		pages, err = utils.ParseMediaWikiDump(f, *emailDomain)
+		f.Close()
+	case *markdownDir != "" && *mediaWiki == "":
+		if *author == "" {
+			log.Println("-markdown needs -author")
+			return 2
+		}
+		pages, err = utils.ReadMarkdownDir(os.DirFS(*markdownDir), *author)
+	default:
+		log.Println("import needs one of -mediawiki or -markdown")
+		return 2
+	}
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	policy, err := utils.GetSnapshotPolicy()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	report, err := Import(context.Background(), db, store, policy, pages, ImportOptions{
+		CreateCategories: *createCategories,
+		DryRun:           *dryRun,
+	})
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+	if len(report.Problems) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,152 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// MigrateReport counts what MigrateLayout did.
+type MigrateReport struct {
+	Moved     int
+	Skipped   int
+	Unmatched int
+}
+
+// legacyFilename matches revision and snapshot files named <slug>_<uuid>.
+var legacyFilename = regexp.MustCompile(`^.+_([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\.(txt|md)$`)
+
+// MigrateLayout moves a local data directory from the old slug-based layout
+//
+//	pages/<slug>.md
+//	revisions/<slug>_<revision uuid>.txt
+//	snapshots/<slug>_<snapshot uuid>.md
+//
+// to files named by ID alone (see filesystem.LocalStorage). Files that are
+// already in the new layout are left alone, so it's safe to run again after
+// a partial failure. Page files with no matching page in the database are
+// reported and left where they are.
+func MigrateLayout(ctx context.Context, db *sql.DB, dir string) (MigrateReport, error) {
+	var report MigrateReport
+
+	move := func(oldName, newName string) error {
+		oldPath := filepath.Join(dir, oldName)
+		newPath := filepath.Join(dir, newName)
+		if _, err := os.Stat(newPath); err == nil {
+			log.Printf("%s: %s already exists, leaving it alone\n", oldName, newName)
+			report.Skipped++
+			return nil
+		}
+		err := os.Rename(oldPath, newPath)
+		if err != nil {
+			return err
+		}
+		report.Moved++
+		return nil
+	}
+
+	// pages need the database to map slugs to IDs
+	rows, err := db.QueryContext(ctx, `SELECT uuid, slug FROM pages;`)
+	if err != nil {
+		return report, err
+	}
+	pageIds := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var pageId uuid.UUID
+		var slug string
+		err = rows.Scan(&pageId, &slug)
+		if err != nil {
+			rows.Close()
+			return report, err
+		}
+		pageIds[slug] = pageId
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return report, err
+	}
+
+	pageFiles, err := os.ReadDir(filepath.Join(dir, "pages"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return report, err
+	}
+	for _, entry := range pageFiles {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".md" {
+			continue
+		}
+		slug := name[:len(name)-len(".md")]
+		if _, err := uuid.Parse(slug); err == nil {
+			continue
+		}
+		pageId, ok := pageIds[slug]
+		if !ok {
+			log.Printf("pages/%s: no page with slug %q, leaving it alone\n", name, slug)
+			report.Unmatched++
+			continue
+		}
+		err = move(filepath.Join("pages", name), filesystem.GetPageFilename(pageId))
+		if err != nil {
+			return report, err
+		}
+	}
+
+	// revisions and snapshots already carry their ID in the name
+	for _, kind := range []struct {
+		dir      string
+		filename func(uuid.UUID) string
+	}{
+		{"revisions", filesystem.GetRevisionFilename},
+		{"snapshots", filesystem.GetSnapshotFilename},
+	} {
+		entries, err := os.ReadDir(filepath.Join(dir, kind.dir))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return report, err
+		}
+		for _, entry := range entries {
+			match := legacyFilename.FindStringSubmatch(entry.Name())
+			if entry.IsDir() || match == nil {
+				continue
+			}
+			err = move(filepath.Join(kind.dir, entry.Name()), kind.filename(uuid.MustParse(match[1])))
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func migrateLayoutCommand(args []string) int {
+	flags := flag.NewFlagSet("migrate-layout", flag.ExitOnError)
+	dir := flags.String("dir", utils.GetDataDir(), "data directory to migrate")
+	flags.Parse(args)
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+
+	report, err := MigrateLayout(context.Background(), db, *dir)
+	fmt.Printf("moved %d files, skipped %d, %d page files with no matching page\n",
+		report.Moved, report.Skipped, report.Unmatched)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"wiki/database"
+	"wiki/filesystem"
+	"wiki/utils"
+)
+
+// RelinkReport counts what Relink did.
+type RelinkReport struct {
+	Pages     int
+	Links     int
+	Templates int
+}
+
+// Relink rebuilds page_links and page_templates from the current content of
+// every page. Edits keep the tables up to date; this is for pages written
+// before they existed, or after files were restored by hand.
+func Relink(ctx context.Context, db *sql.DB, store filesystem.Storage) (RelinkReport, error) {
+	var report RelinkReport
+
+	pageIds, err := queryIds(ctx, db, `SELECT uuid FROM pages ORDER BY slug;`)
+	if err != nil {
+		return report, err
+	}
+	for _, pageId := range pageIds {
+		content, err := store.GetPage(ctx, pageId)
+		if err != nil {
+			return report, fmt.Errorf("reading page %s: %w", pageId, err)
+		}
+		links := utils.ParseWikiLinks(content)
+		templates := utils.ParseTemplateIncludes(content)
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return report, err
+		}
+		err = database.SetPageLinks(ctx, tx, pageId, links)
+		if err != nil {
+			tx.Rollback()
+			return report, err
+		}
+		err = database.SetPageTemplates(ctx, tx, pageId, templates)
+		if err != nil {
+			tx.Rollback()
+			return report, err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return report, err
+		}
+		report.Pages++
+		report.Links += len(links)
+		report.Templates += len(templates)
+	}
+	return report, nil
+}
+
+func relinkCommand(args []string) int {
+	flags := flag.NewFlagSet("relink", flag.ExitOnError)
+	flags.Parse(args)
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	report, err := Relink(context.Background(), db, store)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	log.Printf("found %d links and %d template includes on %d pages\n", report.Links, report.Templates, report.Pages)
+	return 0
+}
@@ -0,0 +1,270 @@
+package commands
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// ReadBackupManifest reads the archive at path and checks it against its
+// manifest: the version must be one Restore understands, and every entry
+// must be listed with a matching size and checksum.
+func ReadBackupManifest(path string) (*BackupManifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	found := make(map[string]BackupEntry)
+	var manifestData []byte
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Name == backupManifestName {
+			manifestData, err = io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("reading archive: %w", err)
+			}
+			continue
+		}
+		hash := sha256.New()
+		size, err := io.Copy(hash, tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		found[hdr.Name] = BackupEntry{Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))}
+	}
+	if manifestData == nil {
+		return nil, errors.New("archive has no manifest")
+	}
+
+	var manifest BackupManifest
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	if manifest.Version != backupVersion {
+		return nil, fmt.Errorf("archive is version %d, only version %d can be restored", manifest.Version, backupVersion)
+	}
+	for name, want := range manifest.Entries {
+		got, ok := found[name]
+		if !ok {
+			return nil, fmt.Errorf("%s is in the manifest but not the archive", name)
+		}
+		if got != want {
+			return nil, fmt.Errorf("%s doesn't match its checksum", name)
+		}
+	}
+	for name := range found {
+		if _, ok := manifest.Entries[name]; !ok {
+			return nil, fmt.Errorf("%s is in the archive but not the manifest", name)
+		}
+	}
+	return &manifest, nil
+}
+
+// Restore loads a backup written by Backup into an empty database and
+// storage. The archive is checked against its manifest before anything is
+// written. Rows are inserted in one transaction, which is committed once all
+// the files are in storage; if the restore fails, storage may be left with
+// some files in it and has to be emptied before trying again.
+func Restore(ctx context.Context, db *sql.DB, store filesystem.Storage, path string) (*BackupManifest, error) {
+	manifest, err := ReadBackupManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range backupTables {
+		var exists bool
+		err = db.QueryRowContext(ctx, fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s);`, table.name)).Scan(&exists)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, fmt.Errorf("%s isn't empty, only an empty database can be restored to", table.name)
+		}
+	}
+	files, err := store.ListFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		return nil, fmt.Errorf("storage has %d files, only empty storage can be restored to", len(files))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// pages are inserted before their revisions with no last revision, and
+	// it's set back once the revisions are in
+	lastRevisions := make(map[uuid.UUID]*uuid.UUID)
+	tr := tar.NewReader(file)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Name == backupManifestName {
+			continue
+		}
+		if table, ok := strings.CutPrefix(hdr.Name, "db/"); ok {
+			err = restoreTable(ctx, tx, strings.TrimSuffix(table, ".jsonl"), tr, lastRevisions)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		ref, ok := parseBackupFilePath(hdr.Name)
+		if !ok {
+			return nil, fmt.Errorf("unexpected file %s in archive", hdr.Name)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		switch ref.kind {
+		case filesystem.PageFile:
+			err = store.PutPage(ctx, ref.page, string(data))
+		case filesystem.RevisionFile:
+			err = store.PutRevision(ctx, ref.page, ref.id, string(data))
+		case filesystem.SnapshotFile:
+			err = store.PutSnapshot(ctx, ref.page, ref.id, string(data))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("writing %s: %w", hdr.Name, err)
+		}
+	}
+
+	for pageId, revId := range lastRevisions {
+		_, err = tx.ExecContext(ctx, `UPDATE pages SET last_revision_id=$1 WHERE uuid=$2;`, revId, pageId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, `
+		SELECT setval(pg_get_serial_sequence('categories', 'id'), COALESCE(MAX(id), 0) + 1, false)
+		FROM categories;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, tx.Commit()
+}
+
+// restoreTable inserts the rows of one db/<table>.jsonl entry. The
+// revisions trigger moves last_revision_id as revisions go in, so the
+// backed-up value of each page's is kept in lastRevisions.
+func restoreTable(ctx context.Context, tx *sql.Tx, name string, r io.Reader, lastRevisions map[uuid.UUID]*uuid.UUID) error {
+	known := false
+	for _, table := range backupTables {
+		known = known || table.name == name
+	}
+	if !known {
+		return fmt.Errorf("unexpected table %s in archive", name)
+	}
+
+	insert := fmt.Sprintf(`INSERT INTO %[1]s SELECT * FROM jsonb_populate_record(NULL::%[1]s, $1::jsonb);`, name)
+	if name == "pages" {
+		insert = `INSERT INTO pages SELECT * FROM jsonb_populate_record(NULL::pages, $1::jsonb - 'last_revision_id');`
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64<<20)
+	for scanner.Scan() {
+		row := scanner.Bytes()
+		if len(bytes.TrimSpace(row)) == 0 {
+			continue
+		}
+		if name == "pages" {
+			var page struct {
+				UUID           uuid.UUID  `json:"uuid"`
+				LastRevisionId *uuid.UUID `json:"last_revision_id"`
+			}
+			err := json.Unmarshal(row, &page)
+			if err != nil {
+				return fmt.Errorf("reading pages: %w", err)
+			}
+			lastRevisions[page.UUID] = page.LastRevisionId
+		}
+		_, err := tx.ExecContext(ctx, insert, string(row))
+		if err != nil {
+			return fmt.Errorf("restoring %s: %w", name, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func restoreCommand(args []string) int {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	check := flags.Bool("check", false, "only check the archive against its manifest")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Println("usage: restore [-check] <archive>")
+		return 2
+	}
+	path := flags.Arg(0)
+
+	if *check {
+		manifest, err := ReadBackupManifest(path)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		log.Printf("archive from %s is intact (%d entries)\n", manifest.CreatedAt.Format("2006-01-02 15:04:05"), len(manifest.Entries))
+		return 0
+	}
+
+	db, err := utils.GetDatabase()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	defer db.Close()
+	store, err := utils.GetStorage()
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	manifest, err := Restore(context.Background(), db, store, path)
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	log.Printf("restored %d pages, %d revisions, %d snapshots and %d files\n",
+		manifest.Tables["pages"], manifest.Tables["revisions"], manifest.Tables["snapshots"], manifest.Files)
+	return 0
+}
@@ -3,8 +3,12 @@ package database
 import (
 	"context"
 	"database/sql"
+	"maps"
+	"slices"
 	"strings"
 	wikierrors "wiki/errors"
+
+	"github.com/lib/pq"
 )
 
 type Category struct {
@@ -191,13 +195,17 @@ func GetPageCategories(ctx context.Context, db *sql.DB, pageId string) ([]Catego
 	return cats, nil
 }
 
-func SetPageCategories(ctx context.Context, db *sql.DB, pageId string, categorySlugs []string) error {
+// SetPageCategories replaces a page's categories. If they changed, watchers
+// of the page and of the categories it's now in or was in hear about it,
+// other than author, who made the change.
+func SetPageCategories(ctx context.Context, db *sql.DB, pageId string, categorySlugs []string, author string) error {
 	pageUUID, err := GetUUID(ctx, db, pageId)
 	if err != nil {
 		return wikierrors.PageNotFound()
 	}
 
 	var catIDs []int
+	var fullSlugs []string
 	seen := make(map[int]struct{}, len(categorySlugs))
 	for _, slug := range categorySlugs {
 		cat, err := GetCategoryBySlugPath(ctx, db, slug)
@@ -209,6 +217,7 @@ func SetPageCategories(ctx context.Context, db *sql.DB, pageId string, categoryS
 		}
 		seen[cat.ID] = struct{}{}
 		catIDs = append(catIDs, cat.ID)
+		fullSlugs = append(fullSlugs, cat.FullSlug)
 	}
 
 	tx, err := db.BeginTx(ctx, nil)
@@ -217,6 +226,18 @@ func SetPageCategories(ctx context.Context, db *sql.DB, pageId string, categoryS
 	}
 	defer tx.Rollback()
 
+	var old pq.Int64Array
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(category ORDER BY category), '{}') FROM page_categories WHERE page_id = $1;
+	`, pageUUID).Scan(&old)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	oldIDs := make([]int, len(old))
+	for i, id := range old {
+		oldIDs[i] = int(id)
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		DELETE FROM page_categories
 		WHERE page_id = $1;
@@ -235,20 +256,34 @@ func SetPageCategories(ctx context.Context, db *sql.DB, pageId string, categoryS
 		}
 	}
 
+	newIDs := slices.Sorted(maps.Keys(seen))
+	if !slices.Equal(oldIDs, newIDs) {
+		summary := "Removed from all categories"
+		if len(fullSlugs) > 0 {
+			summary = "Categories: " + strings.Join(fullSlugs, ", ")
+		}
+		err = NotifyWatchers(ctx, tx, pageUUID, nil, NotifyCategories, author, summary, oldIDs)
+		if err != nil {
+			return wikierrors.DatabaseError(err)
+		}
+	}
+
 	return tx.Commit()
 }
 
 func isValidSlugPath(path string) bool {
 	parts := strings.SplitSeq(path, "/")
 	for part := range parts {
-		if !isValidSlug(part) {
+		if !IsValidSlug(part) {
 			return false
 		}
 	}
 	return true
 }
 
-func isValidSlug(slug string) bool {
+// IsValidSlug reports whether slug is lowercase letters and digits in
+// hyphen-separated words.
+func IsValidSlug(slug string) bool {
 	if slug == "" || strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") || strings.Contains(slug, "--") {
 		return false
 	}
@@ -267,3 +302,70 @@ func computeFullSlug(path string) string {
 	}
 	return strings.Join(parts, "/")
 }
+
+// GetCategoryBySlug finds a category by its own slug, wherever it sits in the
+// tree; category slugs are unique.
+func GetCategoryBySlug(ctx context.Context, db *sql.DB, slug string) (*Category, error) {
+	var cat Category
+	err := db.QueryRowContext(ctx, `
+		SELECT id, slug, name, parent_id, path
+		FROM categories
+		WHERE slug = $1
+	`, slug).Scan(&cat.ID, &cat.Slug, &cat.Name, &cat.ParentID, &cat.Path)
+	if err == sql.ErrNoRows {
+		return nil, wikierrors.CategoryNotFound()
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+
+	cat.FullSlug = computeFullSlug(cat.Path)
+	return &cat, nil
+}
+
+// CreateCategoryPath makes sure every category along slugPath exists,
+// creating the missing ones named after their slugs, and returns the last.
+func CreateCategoryPath(ctx context.Context, db *sql.DB, slugPath string) (*Category, error) {
+	if !isValidSlugPath(slugPath) {
+		return nil, wikierrors.InvalidCatSlug()
+	}
+
+	var parent *Category
+	parts := strings.Split(slugPath, "/")
+	for i, slug := range parts {
+		cat, err := GetCategoryBySlugPath(ctx, db, strings.Join(parts[:i+1], "/"))
+		if err == nil {
+			parent = cat
+			continue
+		}
+		if !wikierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		cat = &Category{Slug: slug, Name: categoryNameFromSlug(slug), Path: "root." + strings.Join(parts[:i+1], ".")}
+		var parentId *int
+		if parent != nil {
+			parentId = &parent.ID
+		}
+		err = db.QueryRowContext(ctx, `
+			INSERT INTO categories (slug, name, parent_id, path)
+			VALUES ($1, $2, $3, $4::ltree)
+			RETURNING id
+		`, cat.Slug, cat.Name, parentId, cat.Path).Scan(&cat.ID)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		cat.ParentID = parentId
+		cat.FullSlug = computeFullSlug(cat.Path)
+		parent = cat
+	}
+	return parent, nil
+}
+
+func categoryNameFromSlug(slug string) string {
+	words := strings.Split(slug, "-")
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
@@ -23,6 +23,9 @@ type RevInfo struct {
 	Name		string		`db:"name" json:"name"`
 	ArchiveDate	*time.Time	`db:"archive_date" json:"archive_date"`
 	DeletedAt	*time.Time	`db:"deleted_at" json:"deleted_at"`
+	RevertedFrom	*uuid.UUID	`db:"reverted_from" json:"reverted_from"`
+	Summary		string		`db:"summary" json:"summary"`
+	Minor		bool		`db:"minor" json:"minor"`
 }
 
 type SnapInfo struct {
@@ -30,3 +33,73 @@ type SnapInfo struct {
 	Page		uuid.UUID	`db:"page" json:"page"`
 	Revision	*uuid.UUID	`db:"revision" json:"revision"`
 }
+
+type RedirectInfo struct {
+	Slug		string		`db:"slug" json:"slug"`
+	PageId		uuid.UUID	`db:"page_id" json:"page_id"`
+	Alias		bool		`db:"alias" json:"alias"`
+	CreatedBy	*string		`db:"created_by" json:"created_by"`
+	CreatedAt	time.Time	`db:"created_at" json:"created_at"`
+}
+
+type PageLink struct {
+	UUID	uuid.UUID	`db:"uuid" json:"uuid"`
+	Slug	string		`db:"slug" json:"slug"`
+	Name	string		`db:"name" json:"name"`
+}
+
+type ReindexEntry struct {
+	UUID		uuid.UUID	`db:"uuid" json:"uuid"`
+	Slug		string		`db:"slug" json:"slug"`
+	MarkedAt	time.Time	`db:"marked_at" json:"marked_at"`
+}
+
+type Draft struct {
+	UUID			uuid.UUID	`db:"uuid" json:"uuid"`
+	PageId			uuid.UUID	`db:"page_id" json:"page_id"`
+	Slug			string		`db:"slug" json:"slug"`
+	Name			string		`db:"name" json:"name"`
+	Author			string		`db:"author" json:"author"`
+	BaseRevision	*uuid.UUID	`db:"base_revision" json:"base_revision"`
+	Content			string		`db:"content" json:"content,omitempty"`
+	CreatedAt		time.Time	`db:"created_at" json:"created_at"`
+	UpdatedAt		time.Time	`db:"updated_at" json:"updated_at"`
+}
+
+type Protection struct {
+	PageId			uuid.UUID	`db:"page_id" json:"page_id"`
+	Slug			string		`db:"slug" json:"slug"`
+	Name			string		`db:"name" json:"name"`
+	Level			string		`db:"level" json:"level"`
+	MinAccountDays	int			`db:"min_account_days" json:"min_account_days"`
+	ExpiresAt		*time.Time	`db:"expires_at" json:"expires_at"`
+	ProtectedBy		string		`db:"protected_by" json:"protected_by"`
+	Reason			string		`db:"reason" json:"reason"`
+	ProtectedAt		time.Time	`db:"protected_at" json:"protected_at"`
+}
+
+// Watch is a page or category subtree a user follows. Exactly one of the
+// page and category fields is set.
+type Watch struct {
+	UUID			uuid.UUID	`db:"uuid" json:"uuid"`
+	PageId			*uuid.UUID	`db:"page_id" json:"page_id"`
+	Slug			*string		`db:"slug" json:"slug"`
+	Name			*string		`db:"name" json:"name"`
+	CategoryId		*int		`db:"category_id" json:"category_id"`
+	CategorySlug	*string		`db:"category_slug" json:"category_slug"`
+	CategoryName	*string		`db:"category_name" json:"category_name"`
+	CreatedAt		time.Time	`db:"created_at" json:"created_at"`
+}
+
+type Notification struct {
+	UUID		uuid.UUID	`db:"uuid" json:"uuid"`
+	PageId		uuid.UUID	`db:"page_id" json:"page_id"`
+	Slug		string		`db:"slug" json:"slug"`
+	Name		string		`db:"name" json:"name"`
+	RevisionId	*uuid.UUID	`db:"revision_id" json:"revision_id"`
+	Kind		string		`db:"kind" json:"kind"`
+	Actor		string		`db:"actor" json:"actor"`
+	Summary		string		`db:"summary" json:"summary"`
+	CreatedAt	time.Time	`db:"created_at" json:"created_at"`
+	ReadAt		*time.Time	`db:"read_at" json:"read_at"`
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// GetDraft returns an author's draft of a page, or sql.ErrNoRows.
+func GetDraft(ctx context.Context, db *sql.DB, author string, pageId uuid.UUID) (*Draft, error) {
+	var d Draft
+	err := db.QueryRowContext(ctx, `
+		SELECT d.uuid, d.page_id, p.slug, p.name, d.author, d.base_revision, d.content, d.created_at, d.updated_at
+		FROM drafts d
+		JOIN pages p ON p.uuid = d.page_id
+		WHERE d.author=$1 AND d.page_id=$2;
+	`, author, pageId).Scan(&d.UUID, &d.PageId, &d.Slug, &d.Name, &d.Author, &d.BaseRevision,
+		&d.Content, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetDrafts lists an author's drafts, newest first, without their content.
+// Drafts of deleted pages are left out.
+func GetDrafts(ctx context.Context, db *sql.DB, author string) ([]Draft, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT d.uuid, d.page_id, p.slug, p.name, d.author, d.base_revision, d.created_at, d.updated_at
+		FROM drafts d
+		JOIN pages p ON p.uuid = d.page_id
+		WHERE d.author=$1 AND p.deleted_at IS NULL
+		ORDER BY d.updated_at DESC;
+	`, author)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	drafts := []Draft{}
+	for rows.Next() {
+		var d Draft
+		err = rows.Scan(&d.UUID, &d.PageId, &d.Slug, &d.Name, &d.Author, &d.BaseRevision, &d.CreatedAt, &d.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		drafts = append(drafts, d)
+	}
+	return drafts, rows.Err()
+}
+
+// SaveDraft creates an author's draft of a page, or replaces the one they
+// have.
+func SaveDraft(ctx context.Context, db *sql.DB, author string, pageId uuid.UUID, baseRevision *uuid.UUID, content string) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO drafts (page_id, author, base_revision, content)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (author, page_id) DO UPDATE
+		SET base_revision = EXCLUDED.base_revision, content = EXCLUDED.content, updated_at = now();
+	`, pageId, author, baseRevision, content)
+	return err
+}
+
+// DeleteDraft discards an author's draft of a page. It returns false if
+// there wasn't one.
+func DeleteDraft(ctx context.Context, db *sql.DB, author string, pageId uuid.UUID) (bool, error) {
+	res, err := db.ExecContext(ctx, `
+		DELETE FROM drafts WHERE author=$1 AND page_id=$2;
+	`, author, pageId)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -40,7 +40,7 @@ func GetPageRevisionsInfo(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]
 	var revs []RevInfo
 	rows, err := db.QueryContext(
 		ctx,
-		`SELECT uuid, date_time, author, slug, name, archive_date, deleted_at 
+		`SELECT uuid, date_time, author, slug, name, archive_date, deleted_at, reverted_from, summary, minor
 				FROM revisions WHERE page_id=$1 ORDER BY date_time`,
 		pageId.String())
 	if err != nil {
@@ -50,7 +50,7 @@ func GetPageRevisionsInfo(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]
 
 	for rows.Next() {
 		var row RevInfo
-		err := rows.Scan(&row.UUID, &row.DateTime, &row.Author, &row.Slug, &row.Name, &row.ArchiveDate, &row.DeletedAt)
+		err := rows.Scan(&row.UUID, &row.DateTime, &row.Author, &row.Slug, &row.Name, &row.ArchiveDate, &row.DeletedAt, &row.RevertedFrom, &row.Summary, &row.Minor)
 		if err != nil {
 			return nil, err
 		}
@@ -64,64 +64,31 @@ func GetRevisionInfo(ctx context.Context, db *sql.DB, revId uuid.UUID) (*RevInfo
 	var rev RevInfo
 	err := db.QueryRowContext(
 		ctx,
-		`SELECT uuid, page_id, date_time, author, slug, name, archive_date, deleted_at 
+		`SELECT uuid, page_id, date_time, author, slug, name, archive_date, deleted_at, reverted_from, summary, minor
 				FROM revisions WHERE uuid=$1`,
-		revId).Scan(&rev.UUID, &rev.PageId, &rev.DateTime, &rev.Author, &rev.Slug, &rev.Name, &rev.ArchiveDate, &rev.DeletedAt)
+		revId).Scan(&rev.UUID, &rev.PageId, &rev.DateTime, &rev.Author, &rev.Slug, &rev.Name, &rev.ArchiveDate, &rev.DeletedAt, &rev.RevertedFrom, &rev.Summary, &rev.Minor)
 	if err != nil {
 		return nil, err
 	}
 	return &rev, nil
 }
 
+// GetMostRecentSnapshot returns the latest snapshot of the revision's page
+// taken at or before that revision.
 func GetMostRecentSnapshot(ctx context.Context, db *sql.DB, revId uuid.UUID) (*SnapInfo, error) {
 	revInfo, err := GetRevisionInfo(ctx, db, revId)
 	if err != nil {
 		return nil, err
 	}
-	pageId := revInfo.PageId
-	var snapId uuid.UUID
 	var snap SnapInfo
-	var snapCount int
 	err = db.QueryRowContext(
 		ctx,
-		`SELECT COUNT(*) FROM snapshots
-		WHERE page=$1`,
-		pageId).
-		Scan(&snapCount)
-	if err != nil {
-		return nil, err
-	}
-	switch snapCount {
-	case 1:
-		err = db.QueryRowContext(
-			ctx,
-			`SELECT uuid FROM snapshots
-			WHERE page=$1`,
-			pageId).
-			Scan(&snapId)
-		if err != nil {
-			return nil, err
-		}
-	case 0:
-		return nil, sql.ErrNoRows
-	default:
-		err = db.QueryRowContext(
-			ctx,
-			`SELECT snapshots.uuid FROM snapshots
-			JOIN revisions ON snapshots.revision = revisions.uuid
-			WHERE snapshots.page=$1
-			ORDER BY revisions.date_time
-			DESC`,
-			pageId).
-			Scan(&snapId)
-		if err != nil {
-			return nil, err
-		}
-	}
-	err = db.QueryRowContext(
-		ctx,
-		"SELECT * FROM snapshots WHERE uuid=$1",
-		snapId).
+		`SELECT s.uuid, s.page, s.revision FROM snapshots s
+		LEFT JOIN revisions r ON s.revision = r.uuid
+		WHERE s.page=$1 AND (r.date_time IS NULL OR r.date_time <= $2)
+		ORDER BY r.date_time DESC NULLS LAST
+		LIMIT 1`,
+		revInfo.PageId, revInfo.DateTime).
 		Scan(&snap.UUID, &snap.Page, &snap.Revision)
 	if err != nil {
 		return nil, err
@@ -189,3 +156,25 @@ func GetMissingRevisions(ctx context.Context, db *sql.DB, revId uuid.UUID) ([]Re
 
 	return revs, nil
 }
+
+func GetPageSnapshots(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]SnapInfo, error) {
+	var snaps []SnapInfo
+	rows, err := db.QueryContext(
+		ctx,
+		"SELECT uuid, page, revision FROM snapshots WHERE page=$1",
+		pageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var snap SnapInfo
+		err := rows.Scan(&snap.UUID, &snap.Page, &snap.Revision)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps, rows.Err()
+}
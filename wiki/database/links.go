@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetPageLinks replaces the slugs a page links to.
+func SetPageLinks(ctx context.Context, tx *sql.Tx, pageId uuid.UUID, slugs []string) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM page_links WHERE source_id=$1;
+	`, pageId)
+	if err != nil {
+		return err
+	}
+	if len(slugs) == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO page_links (source_id, target_slug)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING;
+	`, pageId, pq.Array(slugs))
+	return err
+}
+
+// GetBacklinks lists the pages, other than deleted ones, that link to a page
+// by its slug or by a slug that redirects to it.
+func GetBacklinks(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]PageLink, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT DISTINCT p.uuid, p.slug, p.name
+		FROM page_links l
+		JOIN pages p ON p.uuid = l.source_id
+		WHERE p.deleted_at IS NULL
+		AND p.uuid <> $1
+		AND (l.target_slug = (SELECT slug FROM pages WHERE uuid=$1)
+			OR l.target_slug IN (SELECT slug FROM slug_redirects WHERE page_id=$1))
+		ORDER BY p.name;
+	`, pageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []PageLink{}
+	for rows.Next() {
+		var l PageLink
+		err = rows.Scan(&l.UUID, &l.Slug, &l.Name)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
+
+// GetMissingLinks lists the slugs a page links to that don't lead to a
+// page, directly or by a redirect. Deleted pages count as missing.
+func GetMissingLinks(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT l.target_slug
+		FROM page_links l
+		WHERE l.source_id=$1
+		AND NOT EXISTS (
+			SELECT 1 FROM pages p
+			WHERE p.slug = l.target_slug AND p.deleted_at IS NULL
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM slug_redirects r
+			JOIN pages p ON p.uuid = r.page_id
+			WHERE r.slug = l.target_slug AND p.deleted_at IS NULL
+		)
+		ORDER BY l.target_slug;
+	`, pageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slugs := []string{}
+	for rows.Next() {
+		var slug string
+		err = rows.Scan(&slug)
+		if err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
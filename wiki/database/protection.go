@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+const protectionColumns = `pp.page_id, p.slug, p.name, pp.level, pp.min_account_days, pp.expires_at,
+	pp.protected_by, pp.reason, pp.protected_at`
+
+func scanProtection(row interface{ Scan(...any) error }, pr *Protection) error {
+	return row.Scan(&pr.PageId, &pr.Slug, &pr.Name, &pr.Level, &pr.MinAccountDays, &pr.ExpiresAt,
+		&pr.ProtectedBy, &pr.Reason, &pr.ProtectedAt)
+}
+
+// GetProtection returns a page's protection, or sql.ErrNoRows if it has
+// none or it has expired.
+func GetProtection(ctx context.Context, db *sql.DB, pageId uuid.UUID) (*Protection, error) {
+	var pr Protection
+	err := scanProtection(db.QueryRowContext(ctx, `
+		SELECT `+protectionColumns+`
+		FROM page_protection pp
+		JOIN pages p ON p.uuid = pp.page_id
+		WHERE pp.page_id=$1 AND (pp.expires_at IS NULL OR pp.expires_at > now());
+	`, pageId), &pr)
+	if err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetProtectedPages lists the pages whose protection hasn't expired, other
+// than deleted ones, most recently protected first.
+func GetProtectedPages(ctx context.Context, db *sql.DB) ([]Protection, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT `+protectionColumns+`
+		FROM page_protection pp
+		JOIN pages p ON p.uuid = pp.page_id
+		WHERE (pp.expires_at IS NULL OR pp.expires_at > now()) AND p.deleted_at IS NULL
+		ORDER BY pp.protected_at DESC;
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	protected := []Protection{}
+	for rows.Next() {
+		var pr Protection
+		if err := scanProtection(rows, &pr); err != nil {
+			return nil, err
+		}
+		protected = append(protected, pr)
+	}
+	return protected, rows.Err()
+}
+
+// SetProtection protects a page, replacing any protection it had.
+func SetProtection(ctx context.Context, db *sql.DB, pr Protection) error {
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO page_protection (page_id, level, min_account_days, expires_at, protected_by, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (page_id) DO UPDATE
+		SET level=EXCLUDED.level, min_account_days=EXCLUDED.min_account_days, expires_at=EXCLUDED.expires_at,
+			protected_by=EXCLUDED.protected_by, reason=EXCLUDED.reason, protected_at=now();
+	`, pr.PageId, pr.Level, pr.MinAccountDays, pr.ExpiresAt, pr.ProtectedBy, pr.Reason)
+	return err
+}
+
+// RemoveProtection opens a page to all contributors again.
+func RemoveProtection(ctx context.Context, db *sql.DB, pageId uuid.UUID) error {
+	_, err := db.ExecContext(ctx, `
+		DELETE FROM page_protection WHERE page_id=$1;
+	`, pageId)
+	return err
+}
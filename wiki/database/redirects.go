@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// GetRedirectTarget returns the page an old slug or alias redirects to, or
+// sql.ErrNoRows if there's no redirect from slug.
+func GetRedirectTarget(ctx context.Context, db *sql.DB, slug string) (uuid.UUID, error) {
+	var pageId uuid.UUID
+	err := db.QueryRowContext(ctx, `
+		SELECT page_id FROM slug_redirects WHERE slug=$1;
+	`, slug).Scan(&pageId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return pageId, nil
+}
+
+// GetPageRedirects lists the slugs that redirect to a page, newest first.
+func GetPageRedirects(ctx context.Context, db *sql.DB, pageId uuid.UUID) ([]RedirectInfo, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT slug, page_id, alias, created_by, created_at
+		FROM slug_redirects
+		WHERE page_id=$1
+		ORDER BY created_at DESC, slug;
+	`, pageId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redirects := []RedirectInfo{}
+	for rows.Next() {
+		var r RedirectInfo
+		err = rows.Scan(&r.Slug, &r.PageId, &r.Alias, &r.CreatedBy, &r.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		redirects = append(redirects, r)
+	}
+	return redirects, rows.Err()
+}
+
+// CreateAlias adds a redirect from slug to a page. It returns false if slug is
+// already a page's slug or redirects somewhere.
+func CreateAlias(ctx context.Context, db *sql.DB, pageId uuid.UUID, slug string, user string) (bool, error) {
+	res, err := db.ExecContext(ctx, `
+		INSERT INTO slug_redirects (slug, page_id, alias, created_by)
+		SELECT $1, $2, true, $3
+		WHERE NOT EXISTS (SELECT 1 FROM pages WHERE slug=$1)
+		ON CONFLICT (slug) DO NOTHING;
+	`, slug, pageId, user)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
+
+// DeleteRedirect removes the redirect from slug to a page. It returns false
+// if there's no such redirect.
+func DeleteRedirect(ctx context.Context, db *sql.DB, pageId uuid.UUID, slug string) (bool, error) {
+	res, err := db.ExecContext(ctx, `
+		DELETE FROM slug_redirects WHERE slug=$1 AND page_id=$2;
+	`, slug, pageId)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n == 1, nil
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SetPageTemplates replaces the slugs of the templates a page includes.
+func SetPageTemplates(ctx context.Context, tx *sql.Tx, pageId uuid.UUID, slugs []string) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM page_templates WHERE page_id=$1;
+	`, pageId)
+	if err != nil {
+		return err
+	}
+	if len(slugs) == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO page_templates (page_id, template_slug)
+		SELECT $1, unnest($2::text[])
+		ON CONFLICT DO NOTHING;
+	`, pageId, pq.Array(slugs))
+	return err
+}
+
+// MarkTemplateIncluders queues the pages that include a template, directly
+// or through other templates, to be indexed again. Includes by one of the
+// template's old slugs count too.
+func MarkTemplateIncluders(ctx context.Context, tx *sql.Tx, templateId uuid.UUID) error {
+	_, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE includers(page_id) AS (
+			SELECT t.page_id FROM page_templates t
+			WHERE t.template_slug = (SELECT slug FROM pages WHERE uuid=$1)
+			OR t.template_slug IN (SELECT slug FROM slug_redirects WHERE page_id=$1)
+			UNION
+			SELECT t.page_id FROM includers i
+			JOIN pages p ON p.uuid = i.page_id
+			JOIN page_templates t ON t.template_slug = p.slug
+		)
+		INSERT INTO reindex_queue (page_id)
+		SELECT page_id FROM includers WHERE page_id <> $1
+		ON CONFLICT (page_id) DO UPDATE SET marked_at = now();
+	`, templateId)
+	return err
+}
+
+// GetReindexQueue lists the pages, other than deleted ones, waiting to be
+// indexed again, oldest first.
+func GetReindexQueue(ctx context.Context, db *sql.DB, count int) ([]ReindexEntry, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.uuid, p.slug, q.marked_at
+		FROM reindex_queue q
+		JOIN pages p ON p.uuid = q.page_id
+		WHERE p.deleted_at IS NULL
+		ORDER BY q.marked_at
+		LIMIT $1;
+	`, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ReindexEntry{}
+	for rows.Next() {
+		var e ReindexEntry
+		err = rows.Scan(&e.UUID, &e.Slug, &e.MarkedAt)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// ClearReindexMark takes a page off the reindex queue, unless it was marked
+// again after markedAt.
+func ClearReindexMark(ctx context.Context, db *sql.DB, pageId uuid.UUID, markedAt time.Time) error {
+	_, err := db.ExecContext(ctx, `
+		DELETE FROM reindex_queue WHERE page_id=$1 AND marked_at <= $2;
+	`, pageId, markedAt)
+	return err
+}
@@ -23,12 +23,17 @@ func GetUUID(ctx context.Context, db *sql.DB, id string) (uuid.UUID, error) {
 	return pageUUID, nil
 }
 
+// getUUIDFromSlug finds the page using slug, or else the page an old slug or
+// alias redirects to.
 func getUUIDFromSlug(ctx context.Context, db *sql.DB, slug string) (uuid.UUID, error) {
 	var pageId uuid.UUID
 	err := db.QueryRowContext(
 			ctx,
 			"SELECT uuid FROM pages WHERE slug=$1", slug).
 			Scan(&pageId)
+	if err == sql.ErrNoRows {
+		pageId, err = GetRedirectTarget(ctx, db, slug)
+	}
 	if err != nil {
 		return uuid.Nil, err
 	}
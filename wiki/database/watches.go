@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Kinds of notifications
+const (
+	NotifyEdit       = "edit"
+	NotifyDelete     = "delete"
+	NotifyCategories = "categories"
+)
+
+// AddWatch makes a user watch a page or, with pageId nil, a category and
+// its subcategories. Watching something twice keeps the first watch.
+func AddWatch(ctx context.Context, db *sql.DB, userId uuid.UUID, email string, pageId *uuid.UUID, categoryId *int) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := db.QueryRowContext(ctx, `
+		WITH added AS (
+			INSERT INTO watches (user_id, user_email, page_id, category_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+			RETURNING uuid
+		)
+		SELECT uuid FROM added
+		UNION ALL
+		SELECT uuid FROM watches
+		WHERE user_id=$1 AND page_id IS NOT DISTINCT FROM $3 AND category_id IS NOT DISTINCT FROM $4
+		LIMIT 1;
+	`, userId, email, pageId, categoryId).Scan(&id)
+	return id, err
+}
+
+// DeleteWatch removes one of a user's watches. It reports whether there
+// was one.
+func DeleteWatch(ctx context.Context, db *sql.DB, userId uuid.UUID, id uuid.UUID) (bool, error) {
+	res, err := db.ExecContext(ctx, `
+		DELETE FROM watches WHERE uuid=$1 AND user_id=$2;
+	`, id, userId)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetWatches lists a user's watches, newest first.
+func GetWatches(ctx context.Context, db *sql.DB, userId uuid.UUID) ([]Watch, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT w.uuid, w.page_id, p.slug, p.name, w.category_id, c.path, c.name, w.created_at
+		FROM watches w
+		LEFT JOIN pages p ON p.uuid = w.page_id
+		LEFT JOIN categories c ON c.id = w.category_id
+		WHERE w.user_id=$1
+		ORDER BY w.created_at DESC;
+	`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	watches := []Watch{}
+	for rows.Next() {
+		var w Watch
+		var path *string
+		err = rows.Scan(&w.UUID, &w.PageId, &w.Slug, &w.Name, &w.CategoryId, &path, &w.CategoryName, &w.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if path != nil {
+			slug := computeFullSlug(*path)
+			w.CategorySlug = &slug
+		}
+		watches = append(watches, w)
+	}
+	return watches, rows.Err()
+}
+
+// NotifyWatchers adds a notification for everyone watching a page, directly
+// or through one of its categories or their parents, other than the user
+// who made the change. categoryIds are more categories to count as the
+// page's, like the ones it was just taken out of.
+func NotifyWatchers(ctx context.Context, tx *sql.Tx, pageId uuid.UUID, revisionId *uuid.UUID, kind string,
+	actor string, summary string, categoryIds []int) error {
+	_, err := tx.ExecContext(ctx, `
+		WITH page_cats AS (
+			SELECT category AS id FROM page_categories WHERE page_id=$1
+			UNION
+			SELECT unnest($6::int[])
+		),
+		watching AS (
+			SELECT DISTINCT w.user_id
+			FROM watches w
+			WHERE w.user_email <> $4
+			AND (
+				w.page_id = $1
+				OR w.category_id IN (
+					SELECT a.id
+					FROM page_cats pc
+					JOIN categories c ON c.id = pc.id
+					JOIN categories a ON c.path <@ a.path
+				)
+			)
+		)
+		INSERT INTO notifications (user_id, page_id, revision_id, kind, actor, summary)
+		SELECT user_id, $1, $2::uuid, $3::text, $4::text, $5::text FROM watching;
+	`, pageId, revisionId, kind, actor, summary, pq.Array(categoryIds))
+	return err
+}
+
+// GetNotifications lists a user's notifications, newest first.
+func GetNotifications(ctx context.Context, db *sql.DB, userId uuid.UUID, unreadOnly bool, index int, count int) ([]Notification, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT n.uuid, n.page_id, p.slug, p.name, n.revision_id, n.kind, n.actor, n.summary, n.created_at, n.read_at
+		FROM notifications n
+		JOIN pages p ON p.uuid = n.page_id
+		WHERE n.user_id=$1 AND (NOT $2 OR n.read_at IS NULL)
+		ORDER BY n.created_at DESC, n.uuid DESC
+		OFFSET $3 LIMIT $4;
+	`, userId, unreadOnly, index, count)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		err = rows.Scan(&n.UUID, &n.PageId, &n.Slug, &n.Name, &n.RevisionId, &n.Kind, &n.Actor, &n.Summary,
+			&n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+// CountUnreadNotifications counts the notifications a user hasn't read.
+func CountUnreadNotifications(ctx context.Context, db *sql.DB, userId uuid.UUID) (int, error) {
+	var n int
+	err := db.QueryRowContext(ctx, `
+		SELECT count(*) FROM notifications WHERE user_id=$1 AND read_at IS NULL;
+	`, userId).Scan(&n)
+	return n, err
+}
+
+// MarkNotificationsRead marks some of a user's notifications read, or all
+// of them if ids is empty.
+func MarkNotificationsRead(ctx context.Context, db *sql.DB, userId uuid.UUID, ids []uuid.UUID) error {
+	strIds := make([]string, len(ids))
+	for i, id := range ids {
+		strIds[i] = id.String()
+	}
+	_, err := db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = now()
+		WHERE user_id=$1 AND read_at IS NULL
+		AND (cardinality($2::uuid[]) = 0 OR uuid = ANY($2::uuid[]));
+	`, userId, pq.Array(strIds))
+	return err
+}
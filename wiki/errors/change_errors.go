@@ -0,0 +1,11 @@
+package errors
+
+import "net/http"
+
+const (
+	invalidChangeFilter = "InvalidChangeFilter"
+)
+
+func InvalidChangeFilter(details string) WikiError {
+	return WikiError{http.StatusBadRequest, invalidChangeFilter, details, nil}
+}
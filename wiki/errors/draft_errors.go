@@ -0,0 +1,16 @@
+package errors
+
+import "net/http"
+
+const (
+	draftNotFound = "DraftNotFound"
+	userRequired  = "UserRequired"
+)
+
+func DraftNotFound() WikiError {
+	return WikiError{http.StatusNotFound, draftNotFound, "draft not found", nil}
+}
+
+func UserRequired() WikiError {
+	return WikiError{http.StatusUnauthorized, userRequired, "user is required", nil}
+}
@@ -26,10 +26,12 @@ const (
 	revisionNotFound  	= "RevisionNotFound"
 	snapshotNotFound 	= "SnapshotNotFound"
 	pageDeleted      	= "PageDeleted"
+	pageNotDeleted		= "PageNotDeleted"
 	revisionDeleted  	= "RevisionDeleted"
 	snapshotDeleted  	= "SnapshotDeleted"
 	invalidId        	= "InvalidId"
 	revisionConflict 	= "RevisionConflict"
+	invalidSummary		= "InvalidSummary"
 	internalErr			= "InternalServerError"
 	databaseErr         = "DatabaseError"
 	filesystemErr       = "FilesystemError"
@@ -55,6 +57,15 @@ func RevisionNotFound() WikiError {
 	}
 }
 
+func InvalidSummary(details string) WikiError {
+	return WikiError{
+		http.StatusBadRequest,
+		invalidSummary,
+		details,
+		nil,
+	}
+}
+
 func SnapshotNotFound() WikiError {
 	return WikiError{
 		http.StatusNotFound,
@@ -73,6 +84,15 @@ func PageDeleted() WikiError {
 	}
 }
 
+func PageNotDeleted() WikiError {
+	return WikiError{
+		http.StatusConflict,
+		pageNotDeleted,
+		"page is not deleted",
+		nil,
+	}
+}
+
 func RevisionDeleted() WikiError {
 	return WikiError{
 		http.StatusNotFound,
@@ -177,7 +197,11 @@ func HasType(err error, errType string) bool {
 func IsNotFound(err error) bool {
 	return HasType(err, pageNotFound) ||
 		HasType(err, revisionNotFound) ||
-		HasType(err, snapshotNotFound)
+		HasType(err, snapshotNotFound) ||
+		HasType(err, categoryNotFound) ||
+		HasType(err, redirectNotFound) ||
+		HasType(err, reportNotFound) ||
+		HasType(err, draftNotFound)
 }
 
 func IsDeleted(err error) bool {
@@ -0,0 +1,16 @@
+package errors
+
+import "net/http"
+
+const (
+	pageProtected     = "PageProtected"
+	invalidProtection = "InvalidProtection"
+)
+
+func PageProtected(details string) WikiError {
+	return WikiError{http.StatusForbidden, pageProtected, details, nil}
+}
+
+func InvalidProtection(details string) WikiError {
+	return WikiError{http.StatusBadRequest, invalidProtection, details, nil}
+}
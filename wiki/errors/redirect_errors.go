@@ -0,0 +1,21 @@
+package errors
+
+import "net/http"
+
+const (
+	redirectNotFound = "RedirectNotFound"
+	invalidSlug      = "InvalidSlug"
+	slugTaken        = "SlugTaken"
+)
+
+func RedirectNotFound() WikiError {
+	return WikiError{http.StatusNotFound, redirectNotFound, "redirect not found", nil}
+}
+
+func InvalidSlug() WikiError {
+	return WikiError{http.StatusBadRequest, invalidSlug, "invalid slug format", nil}
+}
+
+func SlugTaken(err error) WikiError {
+	return WikiError{http.StatusConflict, slugTaken, "slug is already in use", err}
+}
@@ -0,0 +1,11 @@
+package errors
+
+import "net/http"
+
+const (
+	invalidMarkedAt = "InvalidMarkedAt"
+)
+
+func InvalidMarkedAt(err error) WikiError {
+	return WikiError{http.StatusBadRequest, invalidMarkedAt, "marked_at must be an RFC 3339 time", err}
+}
@@ -0,0 +1,11 @@
+package errors
+
+import "net/http"
+
+const (
+	reportNotFound = "ReportNotFound"
+)
+
+func ReportNotFound() WikiError {
+	return WikiError{http.StatusNotFound, reportNotFound, "report not found", nil}
+}
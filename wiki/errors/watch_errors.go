@@ -0,0 +1,16 @@
+package errors
+
+import "net/http"
+
+const (
+	watchNotFound = "WatchNotFound"
+	invalidWatch  = "InvalidWatch"
+)
+
+func WatchNotFound() WikiError {
+	return WikiError{http.StatusNotFound, watchNotFound, "watch not found", nil}
+}
+
+func InvalidWatch(details string) WikiError {
+	return WikiError{http.StatusBadRequest, invalidWatch, details, nil}
+}
@@ -1,59 +1,49 @@
 package filesystem
 
 import (
-	"context"
-	"database/sql"
 	"fmt"
 	"path/filepath"
-	"wiki/database"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
-func GetPageFilename(ctx context.Context, db *sql.DB, dataDir string, id string) (string, error) {
-	uuid, err := database.GetUUID(ctx, db, id)
-	if err != nil {
-		return "", err
-	}
-	var slug string
-	err = db.QueryRowContext(ctx, `
-		SELECT slug
-		FROM pages
-		WHERE uuid=$1;
-	`, uuid).Scan(&slug)
-	if err != nil {
-		return "", err
-	}
-	filename := filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", slug))
-	return filename, nil
+// GetPageFilename is the name of a page's file, relative to the storage root.
+func GetPageFilename(pageId uuid.UUID) string {
+	return filepath.Join("pages", fmt.Sprintf("%s.md", pageId))
 }
 
-func GetRevisionFilename(ctx context.Context, db *sql.DB, dataDir string, uuid uuid.UUID) (string, error) {
-	var slug string
-	err := db.QueryRowContext(ctx, `
-		SELECT slug
-		FROM revisions
-		WHERE uuid=$1
-		LIMIT 1;
-	`, uuid).Scan(&slug)
-	if err != nil {
-		return "", err
-	}
-	filename := filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", slug, uuid))
-	return filename, nil
+// GetRevisionFilename is the name of a revision's diff file, relative to the
+// storage root.
+func GetRevisionFilename(revId uuid.UUID) string {
+	return filepath.Join("revisions", fmt.Sprintf("%s.txt", revId))
 }
 
-func GetSnapshotFilename(ctx context.Context, db *sql.DB, dataDir string, uuid uuid.UUID) (string, error) {
-	var slug string
-	err := db.QueryRowContext(ctx, `
-		SELECT revisions.slug
-		FROM snapshots JOIN revisions ON snapshots.revision = revisions.uuid
-		WHERE snapshots.uuid=$1
-		LIMIT 1;
-	`, uuid).Scan(&slug)
-	if err != nil {
-		return "", err
+// GetSnapshotFilename is the name of a snapshot's file, relative to the
+// storage root.
+func GetSnapshotFilename(snapId uuid.UUID) string {
+	return filepath.Join("snapshots", fmt.Sprintf("%s.md", snapId))
+}
+
+// parseFilename works out which file a storage-relative name refers to. It
+// returns false for names outside the pages, revisions and snapshots
+// directories.
+func parseFilename(name string) (StoredFile, bool) {
+	file := StoredFile{Name: name}
+	dir, base := filepath.Split(filepath.ToSlash(name))
+	var ext string
+	switch strings.TrimSuffix(dir, "/") {
+	case "pages":
+		file.Kind, ext = PageFile, ".md"
+	case "revisions":
+		file.Kind, ext = RevisionFile, ".txt"
+	case "snapshots":
+		file.Kind, ext = SnapshotFile, ".md"
+	default:
+		return file, false
+	}
+	if id, err := uuid.Parse(strings.TrimSuffix(base, ext)); err == nil && strings.HasSuffix(base, ext) {
+		file.ID = id
 	}
-	filename := filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", slug, uuid))
-	return filename, nil
+	return file, true
 }
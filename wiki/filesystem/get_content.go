@@ -2,55 +2,29 @@ package filesystem
 
 import (
 	"context"
-	"database/sql"
-	"os"
 	"regexp"
 	"strings"
 
 	"github.com/google/uuid"
 )
 
-func GetPageContent(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID) (string, error) {
-	filename, err := GetPageFilename(ctx, db, dataDir, pageId.String())
-	if err != nil {
-		return "", err
-	}
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "", err
-	}
-	return string(content), nil
+func GetPageContent(ctx context.Context, store Storage, pageId uuid.UUID) (string, error) {
+	return store.GetPage(ctx, pageId)
 }
 
-func GetRevisionContent(ctx context.Context, db *sql.DB, dataDir string, revId uuid.UUID) (string, error) {
+func GetRevisionContent(ctx context.Context, store Storage, pageId uuid.UUID, revId uuid.UUID) (string, error) {
 	if revId == uuid.Nil {
 		return "", nil
 	}
-	filename, err := GetRevisionFilename(ctx, db, dataDir, revId)
-	if err != nil {
-		return "", err
-	}
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "", err
-	}
-	return string(content), nil
+	return store.GetRevision(ctx, pageId, revId)
 }
 
-func GetSnapshotContent(ctx context.Context, db *sql.DB, dataDir string, snapId uuid.UUID) (string, error) {
-	filename, err := GetSnapshotFilename(ctx, db, dataDir, snapId)
-	if err != nil {
-		return "", err
-	}
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return "", err
-	}
-	return string(content), nil
+func GetSnapshotContent(ctx context.Context, store Storage, pageId uuid.UUID, snapId uuid.UUID) (string, error) {
+	return store.GetSnapshot(ctx, pageId, snapId)
 }
 
-func GetPagePreview(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID, length int) (string, error) {
-	content, err := GetPageContent(ctx, db, dataDir, pageId)
+func GetPagePreview(ctx context.Context, store Storage, pageId uuid.UUID, length int) (string, error) {
+	content, err := GetPageContent(ctx, store, pageId)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,102 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// LocalStorage keeps files in a directory on disk, laid out as
+//
+//	pages/<page uuid>.md
+//	revisions/<revision uuid>.txt
+//	snapshots/<snapshot uuid>.md
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) *LocalStorage {
+	return &LocalStorage{dir: dir}
+}
+
+func (s *LocalStorage) read(name string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (s *LocalStorage) write(name string, content string) error {
+	path := filepath.Join(s.dir, name)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+func (s *LocalStorage) remove(name string) error {
+	return os.Remove(filepath.Join(s.dir, name))
+}
+
+func (s *LocalStorage) GetPage(ctx context.Context, pageId uuid.UUID) (string, error) {
+	return s.read(GetPageFilename(pageId))
+}
+
+func (s *LocalStorage) PutPage(ctx context.Context, pageId uuid.UUID, content string) error {
+	return s.write(GetPageFilename(pageId), content)
+}
+
+func (s *LocalStorage) DeletePage(ctx context.Context, pageId uuid.UUID) error {
+	return s.remove(GetPageFilename(pageId))
+}
+
+func (s *LocalStorage) GetRevision(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.read(GetRevisionFilename(id))
+}
+
+func (s *LocalStorage) PutRevision(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.write(GetRevisionFilename(id), content)
+}
+
+func (s *LocalStorage) DeleteRevision(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.remove(GetRevisionFilename(id))
+}
+
+func (s *LocalStorage) GetSnapshot(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.read(GetSnapshotFilename(id))
+}
+
+func (s *LocalStorage) PutSnapshot(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.write(GetSnapshotFilename(id), content)
+}
+
+func (s *LocalStorage) DeleteSnapshot(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.remove(GetSnapshotFilename(id))
+}
+
+func (s *LocalStorage) ListFiles(ctx context.Context) ([]StoredFile, error) {
+	var files []StoredFile
+	for _, dir := range []string{"pages", "revisions", "snapshots"} {
+		entries, err := os.ReadDir(filepath.Join(s.dir, dir))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			file, _ := parseFilename(filepath.Join(dir, entry.Name()))
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,97 @@
+package filesystem
+
+import (
+	"context"
+	"io/fs"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStorage keeps files in memory, using the same names LocalStorage
+// would. It's meant for tests.
+type MemoryStorage struct {
+	mu    sync.RWMutex
+	files map[string]string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: make(map[string]string)}
+}
+
+func (s *MemoryStorage) read(name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	content, ok := s.files[name]
+	if !ok {
+		return "", &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return content, nil
+}
+
+func (s *MemoryStorage) write(name string, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = content
+	return nil
+}
+
+func (s *MemoryStorage) remove(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.files[name]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(s.files, name)
+	return nil
+}
+
+func (s *MemoryStorage) GetPage(ctx context.Context, pageId uuid.UUID) (string, error) {
+	return s.read(GetPageFilename(pageId))
+}
+
+func (s *MemoryStorage) PutPage(ctx context.Context, pageId uuid.UUID, content string) error {
+	return s.write(GetPageFilename(pageId), content)
+}
+
+func (s *MemoryStorage) DeletePage(ctx context.Context, pageId uuid.UUID) error {
+	return s.remove(GetPageFilename(pageId))
+}
+
+func (s *MemoryStorage) GetRevision(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.read(GetRevisionFilename(id))
+}
+
+func (s *MemoryStorage) PutRevision(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.write(GetRevisionFilename(id), content)
+}
+
+func (s *MemoryStorage) DeleteRevision(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.remove(GetRevisionFilename(id))
+}
+
+func (s *MemoryStorage) GetSnapshot(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.read(GetSnapshotFilename(id))
+}
+
+func (s *MemoryStorage) PutSnapshot(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.write(GetSnapshotFilename(id), content)
+}
+
+func (s *MemoryStorage) DeleteSnapshot(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.remove(GetSnapshotFilename(id))
+}
+
+func (s *MemoryStorage) ListFiles(ctx context.Context) ([]StoredFile, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var files []StoredFile
+	for name := range s.files {
+		if file, ok := parseFilename(name); ok {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
@@ -0,0 +1,303 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// S3Config describes an S3-compatible bucket. Objects are addressed
+// path-style (<endpoint>/<bucket>/<key>), which works with AWS, MinIO and
+// Tigris alike.
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// S3Storage keeps files in an S3-compatible bucket, keyed by UUID:
+//
+//	pages/<page uuid>.md
+//	revisions/<page uuid>/<revision uuid>.txt
+//	snapshots/<page uuid>/<snapshot uuid>.md
+//
+// Page reads are cached in memory for cacheTTL. Expired entries are swept
+// out as new ones go in, so the cache only holds pages read recently.
+type S3Storage struct {
+	config   S3Config
+	client   *http.Client
+	cacheTTL time.Duration
+
+	mu        sync.Mutex
+	pageCache map[uuid.UUID]cachedPage
+	nextSweep time.Time
+}
+
+type cachedPage struct {
+	content string
+	expires time.Time
+}
+
+func NewS3Storage(config S3Config, cacheTTL time.Duration) *S3Storage {
+	config.Endpoint = strings.TrimSuffix(config.Endpoint, "/")
+	return &S3Storage{
+		config:    config,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		cacheTTL:  cacheTTL,
+		pageCache: make(map[uuid.UUID]cachedPage),
+	}
+}
+
+func s3PageKey(pageId uuid.UUID) string {
+	return fmt.Sprintf("pages/%s.md", pageId)
+}
+
+func s3RevisionKey(pageId, revId uuid.UUID) string {
+	return fmt.Sprintf("revisions/%s/%s.txt", pageId, revId)
+}
+
+func s3SnapshotKey(pageId, snapId uuid.UUID) string {
+	return fmt.Sprintf("snapshots/%s/%s.md", pageId, snapId)
+}
+
+// parseS3Key is parseFilename for bucket keys, which put revisions and
+// snapshots under a directory per page.
+func parseS3Key(key string) (StoredFile, bool) {
+	parts := strings.Split(key, "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "pages":
+		file, ok := parseFilename(key)
+		file.Name = key
+		return file, ok
+	case len(parts) == 3 && (parts[0] == "revisions" || parts[0] == "snapshots"):
+		file, ok := parseFilename(parts[0] + "/" + parts[2])
+		if _, err := uuid.Parse(parts[1]); err != nil {
+			file.ID = uuid.Nil
+		}
+		file.Name = key
+		return file, ok
+	case parts[0] == "pages" || parts[0] == "revisions" || parts[0] == "snapshots":
+		return StoredFile{Name: key}, true
+	}
+	return StoredFile{}, false
+}
+
+func (s *S3Storage) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	return s.doURL(ctx, method, fmt.Sprintf("%s/%s/%s", s.config.Endpoint, s.config.Bucket, key), body)
+}
+
+func (s *S3Storage) doURL(ctx context.Context, method, rawURL string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, sha256Hex(body), s.config.AccessKey, s.config.SecretKey, s.config.Region, time.Now())
+	return s.client.Do(req)
+}
+
+// s3Error turns an unexpected response into an error, mapping 404 to
+// fs.ErrNotExist so callers can treat every backend alike.
+func s3Error(op, key string, res *http.Response) error {
+	if res.StatusCode == http.StatusNotFound {
+		return &fs.PathError{Op: op, Path: key, Err: fs.ErrNotExist}
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return &fs.PathError{Op: op, Path: key, Err: fmt.Errorf("s3 returned %s: %s", res.Status, msg)}
+}
+
+func (s *S3Storage) get(ctx context.Context, key string) (string, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", s3Error("open", key, res)
+	}
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func (s *S3Storage) put(ctx context.Context, key string, content string) error {
+	res, err := s.do(ctx, http.MethodPut, key, []byte(content))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error("write", key, res)
+	}
+	return nil
+}
+
+func (s *S3Storage) delete(ctx context.Context, key string) error {
+	// S3 deletes succeed whether or not the object exists, so check first
+	res, err := s.do(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return err
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error("remove", key, res)
+	}
+
+	res, err = s.do(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK {
+		return s3Error("remove", key, res)
+	}
+	return nil
+}
+
+func (s *S3Storage) cachedPage(pageId uuid.UUID) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cached, ok := s.pageCache[pageId]
+	if !ok || time.Now().After(cached.expires) {
+		delete(s.pageCache, pageId)
+		return "", false
+	}
+	return cached.content, true
+}
+
+func (s *S3Storage) cachePage(pageId uuid.UUID, content string) {
+	if s.cacheTTL <= 0 {
+		return
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// sweeping at most once a TTL keeps inserts cheap
+	if now.After(s.nextSweep) {
+		for id, cached := range s.pageCache {
+			if now.After(cached.expires) {
+				delete(s.pageCache, id)
+			}
+		}
+		s.nextSweep = now.Add(s.cacheTTL)
+	}
+	s.pageCache[pageId] = cachedPage{content: content, expires: now.Add(s.cacheTTL)}
+}
+
+func (s *S3Storage) forgetPage(pageId uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pageCache, pageId)
+}
+
+func (s *S3Storage) GetPage(ctx context.Context, pageId uuid.UUID) (string, error) {
+	if content, ok := s.cachedPage(pageId); ok {
+		return content, nil
+	}
+	content, err := s.get(ctx, s3PageKey(pageId))
+	if err != nil {
+		return "", err
+	}
+	s.cachePage(pageId, content)
+	return content, nil
+}
+
+func (s *S3Storage) PutPage(ctx context.Context, pageId uuid.UUID, content string) error {
+	s.forgetPage(pageId)
+	err := s.put(ctx, s3PageKey(pageId), content)
+	if err != nil {
+		return err
+	}
+	s.cachePage(pageId, content)
+	return nil
+}
+
+func (s *S3Storage) DeletePage(ctx context.Context, pageId uuid.UUID) error {
+	s.forgetPage(pageId)
+	return s.delete(ctx, s3PageKey(pageId))
+}
+
+func (s *S3Storage) GetRevision(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.get(ctx, s3RevisionKey(pageId, id))
+}
+
+func (s *S3Storage) PutRevision(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.put(ctx, s3RevisionKey(pageId, id), content)
+}
+
+func (s *S3Storage) DeleteRevision(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.delete(ctx, s3RevisionKey(pageId, id))
+}
+
+func (s *S3Storage) GetSnapshot(ctx context.Context, pageId, id uuid.UUID) (string, error) {
+	return s.get(ctx, s3SnapshotKey(pageId, id))
+}
+
+func (s *S3Storage) PutSnapshot(ctx context.Context, pageId, id uuid.UUID, content string) error {
+	return s.put(ctx, s3SnapshotKey(pageId, id), content)
+}
+
+func (s *S3Storage) DeleteSnapshot(ctx context.Context, pageId, id uuid.UUID) error {
+	return s.delete(ctx, s3SnapshotKey(pageId, id))
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3Storage) ListFiles(ctx context.Context) ([]StoredFile, error) {
+	var files []StoredFile
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		res, err := s.doURL(ctx, http.MethodGet, fmt.Sprintf("%s/%s?%s", s.config.Endpoint, s.config.Bucket, query.Encode()), nil)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			err = s3Error("list", s.config.Bucket, res)
+			res.Body.Close()
+			return nil, err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range result.Contents {
+			if file, ok := parseS3Key(obj.Key); ok {
+				files = append(files, file)
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return files, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
@@ -0,0 +1,47 @@
+package filesystem
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Storage holds the page, revision and snapshot files of the wiki. Files are
+// keyed by stable IDs, so changing a page's slug never touches storage.
+// Revisions and snapshots also take the ID of the page they belong to, for
+// backends that group files by page.
+//
+// Reading or deleting a file that doesn't exist returns an error matching
+// fs.ErrNotExist.
+type Storage interface {
+	GetPage(ctx context.Context, pageId uuid.UUID) (string, error)
+	PutPage(ctx context.Context, pageId uuid.UUID, content string) error
+	DeletePage(ctx context.Context, pageId uuid.UUID) error
+
+	GetRevision(ctx context.Context, pageId, revId uuid.UUID) (string, error)
+	PutRevision(ctx context.Context, pageId, revId uuid.UUID, content string) error
+	DeleteRevision(ctx context.Context, pageId, revId uuid.UUID) error
+
+	GetSnapshot(ctx context.Context, pageId, snapId uuid.UUID) (string, error)
+	PutSnapshot(ctx context.Context, pageId, snapId uuid.UUID, content string) error
+	DeleteSnapshot(ctx context.Context, pageId, snapId uuid.UUID) error
+
+	// ListFiles lists every file in storage, for consistency checks.
+	ListFiles(ctx context.Context) ([]StoredFile, error)
+}
+
+type FileKind string
+
+const (
+	PageFile     FileKind = "page"
+	RevisionFile FileKind = "revision"
+	SnapshotFile FileKind = "snapshot"
+)
+
+// StoredFile is a file found by ListFiles. ID is uuid.Nil if the file's name
+// isn't one the backend would have written.
+type StoredFile struct {
+	Kind FileKind  `json:"kind"`
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
@@ -0,0 +1,102 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// testStorage checks the behaviour every backend has to share.
+func testStorage(t *testing.T, store Storage) {
+	ctx := context.Background()
+	pageId := uuid.New()
+
+	_, err := store.GetPage(ctx, pageId)
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("GetPage on missing page: got %v, want fs.ErrNotExist", err)
+	}
+
+	if err := store.PutPage(ctx, pageId, "page content"); err != nil {
+		t.Fatalf("PutPage: %v", err)
+	}
+	content, err := store.GetPage(ctx, pageId)
+	if err != nil || content != "page content" {
+		t.Errorf("GetPage = %q, %v", content, err)
+	}
+	if err := store.DeletePage(ctx, pageId); err != nil {
+		t.Fatalf("DeletePage: %v", err)
+	}
+	if _, err := store.GetPage(ctx, pageId); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("page still readable after delete: %v", err)
+	}
+
+	revId := uuid.New()
+	if err := store.PutRevision(ctx, pageId, revId, "diff"); err != nil {
+		t.Fatalf("PutRevision: %v", err)
+	}
+	content, err = store.GetRevision(ctx, pageId, revId)
+	if err != nil || content != "diff" {
+		t.Errorf("GetRevision = %q, %v", content, err)
+	}
+	if err := store.DeleteRevision(ctx, pageId, revId); err != nil {
+		t.Fatalf("DeleteRevision: %v", err)
+	}
+	if err := store.DeleteRevision(ctx, pageId, revId); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("deleting a missing revision: got %v, want fs.ErrNotExist", err)
+	}
+
+	snapId := uuid.New()
+	if err := store.PutSnapshot(ctx, pageId, snapId, "snapshot"); err != nil {
+		t.Fatalf("PutSnapshot: %v", err)
+	}
+	content, err = store.GetSnapshot(ctx, pageId, snapId)
+	if err != nil || content != "snapshot" {
+		t.Errorf("GetSnapshot = %q, %v", content, err)
+	}
+
+	files, err := store.ListFiles(ctx)
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].Kind != SnapshotFile || files[0].ID != snapId {
+		t.Errorf("ListFiles = %+v, want just snapshot %s", files, snapId)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	testStorage(t, NewLocalStorage(dir))
+
+	// files are named by ID alone
+	store := NewLocalStorage(dir)
+	revId := uuid.New()
+	if err := store.PutRevision(context.Background(), uuid.New(), revId, "diff"); err != nil {
+		t.Fatalf("PutRevision: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, GetRevisionFilename(revId))); err != nil {
+		t.Errorf("revision file not at %s: %v", GetRevisionFilename(revId), err)
+	}
+}
+
+func TestLocalStorageListUnknownFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "pages"), 0755)
+	os.WriteFile(filepath.Join(dir, "pages", "old-slug.md"), []byte("stray"), 0644)
+
+	files, err := NewLocalStorage(dir).ListFiles(context.Background())
+	if err != nil {
+		t.Fatalf("ListFiles: %v", err)
+	}
+	if len(files) != 1 || files[0].Kind != PageFile || files[0].ID != uuid.Nil {
+		t.Errorf("ListFiles = %+v, want one page file with no ID", files)
+	}
+}
+
+func TestMemoryStorage(t *testing.T) {
+	testStorage(t, NewMemoryStorage())
+}
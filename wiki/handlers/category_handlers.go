@@ -108,7 +108,7 @@ func SetPageCategoriesHandler(c *gin.Context) {
 		return
 	}
 
-	err = database.SetPageCategories(ctx, db, id, categories)
+	err = database.SetPageCategories(ctx, db, id, categories, c.GetHeader(userHeader))
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
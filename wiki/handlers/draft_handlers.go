@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	wikierrors "wiki/errors"
+	"wiki/requests"
+	"wiki/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// userHeader carries the email of the user a draft belongs to. The API layer
+// sets it from the user's token.
+const userHeader = "X-User-Email"
+
+func DraftsHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	drafts, err := requests.GetDrafts(ctx, db, c.GetHeader(userHeader))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, drafts)
+}
+
+func PageDraftHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	draft, err := requests.GetDraft(ctx, db, c.GetHeader(userHeader), c.Param("id"))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+func SaveDraftHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	draft, err := requests.SaveDraft(ctx, db, c.GetHeader(userHeader), c.Param("id"),
+		c.PostForm("base_revision"), c.PostForm("content"))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, draft)
+}
+
+func DeleteDraftHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	err = requests.DeleteDraft(ctx, db, c.GetHeader(userHeader), c.Param("id"))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.Status(http.StatusOK)
+}
@@ -27,7 +27,6 @@ func PagesHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	// URL Parameters
 	catQuery := c.DefaultQuery("category", "")
@@ -48,7 +47,7 @@ func PagesHandler(c *gin.Context) {
 	var pages []utils.PageInfoPrev
 
 	if catQuery != "" {
-		pages, err = requests.GetPagesCategory(ctx, db, dataDir, catQuery, ind, count, exact)
+		pages, err = requests.GetPagesCategory(ctx, db, storage, catQuery, ind, count, exact)
 		if err != nil {
 			werr, is := wikierrors.AsWikiError(err)
 			if !is {
@@ -61,9 +60,9 @@ func PagesHandler(c *gin.Context) {
 		}
 	} else if slugs != "" {
 		slugList := strings.Split(slugs, ",")
-		pages = requests.GetPagesBySlugs(ctx, db, dataDir, slugList)
+		pages = requests.GetPagesBySlugs(ctx, db, storage, slugList)
 	} else {
-		pages, err = requests.GetPages(ctx, db, dataDir, ind, count)
+		pages, err = requests.GetPages(ctx, db, storage, ind, count)
 		if err != nil {
 			werr, is := wikierrors.AsWikiError(err)
 			if !is {
@@ -92,10 +91,9 @@ func PageHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	pageId := c.Param("id")
-	page, err := requests.GetPage(ctx, db, dataDir, pageId)
+	page, err := requests.GetPage(ctx, db, storage, pageId)
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
@@ -168,9 +166,8 @@ func PageRevisionHandler(c *gin.Context) {
 	}
 	defer db.Close()
 
-	dataDir := utils.GetDataDir()
 	revId := c.Param("rev")
-	revision, err := requests.GetRevision(ctx, db, dataDir, revId)
+	revision, err := requests.GetRevision(ctx, db, storage, revId)
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
@@ -206,7 +203,6 @@ func IndexablePagesHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	res, err := db.QueryContext(ctx, `
 		SELECT slug FROM pages WHERE deleted_at IS NULL LIMIT $1 OFFSET $2;
@@ -236,7 +232,7 @@ func IndexablePagesHandler(c *gin.Context) {
 
 	var indexable []utils.IndexInfo
 	for _, page := range slugs {
-		indexInfo, err := utils.GetIndexInfo(ctx, db, dataDir, page)
+		indexInfo, err := utils.GetIndexInfo(ctx, db, storage, page)
 		if err != nil {
 			werr := wikierrors.DatabaseFilesystemError(err)
 			c.AbortWithStatusJSON(werr.Code, gin.H{
@@ -252,3 +248,305 @@ func IndexablePagesHandler(c *gin.Context) {
 
 	c.JSON(http.StatusOK, indexable)
 }
+
+func IndexablePageHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.DatabaseError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	pageId, err := database.GetUUID(ctx, db, c.Param("id"))
+	if err != nil {
+		werr := wikierrors.PageNotFound()
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	pageDeleted, err := database.GetPageDeleted(ctx, db, pageId)
+	if err != nil {
+		werr := wikierrors.DatabaseError(err)
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	if pageDeleted {
+		werr := wikierrors.PageDeleted()
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	indexInfo, err := utils.GetIndexInfo(ctx, db, storage, pageId.String())
+	if err != nil {
+		werr := wikierrors.DatabaseFilesystemError(err)
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, indexInfo)
+}
+
+func DeletedPagesHandler(c *gin.Context) {
+	ind, err := strconv.Atoi(c.DefaultQuery("index", "0"))
+	if err != nil {
+		ind = 0
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "10"))
+	if err != nil {
+		count = 10
+	}
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	pages, err := requests.GetDeletedPages(ctx, db, ind, count)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, pages)
+}
+
+func ReportHandler(c *gin.Context) {
+	ind, err := strconv.Atoi(c.DefaultQuery("index", "0"))
+	if err != nil {
+		ind = 0
+	}
+	count, err := strconv.Atoi(c.DefaultQuery("count", "50"))
+	if err != nil {
+		count = 50
+	}
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	report, err := requests.GetReport(ctx, db, c.Param("report"), ind, count)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func RecentChangesHandler(c *gin.Context) {
+	count, err := strconv.Atoi(c.DefaultQuery("count", "50"))
+	if err != nil {
+		count = 50
+	}
+	filter := requests.ChangeFilter{
+		Since:     c.Query("since"),
+		Until:     c.Query("until"),
+		Category:  c.Query("category"),
+		Page:      c.Query("page"),
+		Namespace: c.Query("namespace"),
+		Author:    c.Query("author"),
+		Minor:     c.Query("minor"),
+		Types:     c.Query("type"),
+		Cursor:    c.Query("cursor"),
+		Count:     count,
+		DiffStats: c.Query("diff_stats") == "true",
+	}
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	changes, err := requests.GetRecentChanges(ctx, db, storage, filter)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, changes)
+}
+
+func RevisionCacheHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, utils.GetRevisionCacheStats())
+}
+
+func PageDiffHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	id := c.Param("id")
+	from := c.Query("from")
+	to := c.Query("to")
+	contextLines, err := strconv.Atoi(c.DefaultQuery("context", "3"))
+	if err != nil || contextLines < 0 {
+		contextLines = 3
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		diff, err := requests.GetRevisionDiff(ctx, db, storage, id, from, to, contextLines)
+		if err != nil {
+			werr, is := wikierrors.AsWikiError(err)
+			if !is {
+				werr = wikierrors.InternalError(err)
+			}
+			c.AbortWithStatusJSON(werr.Code, gin.H{
+				"error": werr.Details,
+			})
+			return
+		}
+		c.JSON(http.StatusOK, diff)
+	case "unified":
+		diff, err := requests.GetUnifiedRevisionDiff(ctx, db, storage, id, from, to, contextLines)
+		if err != nil {
+			werr, is := wikierrors.AsWikiError(err)
+			if !is {
+				werr = wikierrors.InternalError(err)
+			}
+			c.AbortWithStatusJSON(werr.Code, gin.H{
+				"error": werr.Details,
+			})
+			return
+		}
+		c.String(http.StatusOK, diff)
+	default:
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "format must be json or unified",
+		})
+	}
+}
+
+func PageBlameHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	blame, err := requests.GetPageBlame(ctx, db, storage, c.Param("id"), c.Query("rev"))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, blame)
+}
+
+func ExportGitHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Disposition", `attachment; filename="wiki.fi"`)
+	err = requests.ExportHistory(ctx, db, storage, c.Writer, c.Query("page"))
+	if err != nil {
+		// once the stream has started the status can't change; the missing
+		// "done" marks it as incomplete
+		if c.Writer.Written() {
+			c.Error(err)
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	wikierrors "wiki/errors"
+	"wiki/requests"
+	"wiki/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PageBacklinksHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	links, err := requests.GetBacklinks(ctx, db, c.Param("id"))
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
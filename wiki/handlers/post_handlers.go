@@ -13,6 +13,7 @@ import (
 	"wiki/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 func NewPageHandler(c *gin.Context) {
@@ -29,7 +30,6 @@ func NewPageHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	var newPageReq utils.NewPageRequest
 	err = c.Request.ParseMultipartForm(32 << 20)
@@ -95,7 +95,7 @@ func NewPageHandler(c *gin.Context) {
 
 	newPageReq.Content = string(newPageBytes)
 
-	err = utils.CreateNewPage(ctx, db, dataDir, newPageReq)
+	err = utils.CreateNewPage(ctx, db, storage, newPageReq)
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
@@ -123,7 +123,6 @@ func DeletePageHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	var delReq utils.DeletePageRequest
 	err = c.Request.ParseForm()
@@ -143,7 +142,7 @@ func DeletePageHandler(c *gin.Context) {
 	}
 	delReq.User = c.PostForm("user")
 
-	err = requests.DeletePage(ctx, db, dataDir, delReq)
+	err = requests.DeletePage(ctx, db, storage, delReq)
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
@@ -173,7 +172,6 @@ func NewRevisionHandler(c *gin.Context) {
 		return
 	}
 	defer db.Close()
-	dataDir := utils.GetDataDir()
 
 	var revReq utils.RevisionRequest
 	err = c.Request.ParseMultipartForm(32 << 20)
@@ -215,6 +213,27 @@ func NewRevisionHandler(c *gin.Context) {
 		return
 	}
 	revReq.Author = c.PostForm("author")
+	revReq.Summary, err = utils.CleanSummary(c.PostForm("summary"))
+	if err != nil {
+		werr, _ := wikierrors.AsWikiError(err)
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	revReq.Minor = c.PostForm("minor") == "true"
+
+	if baseRevStr := c.PostForm("base_revision"); baseRevStr != "" {
+		baseRev, err := uuid.Parse(baseRevStr)
+		if err != nil {
+			werr := wikierrors.InvalidID(err)
+			c.AbortWithStatusJSON(werr.Code, gin.H{
+				"error": werr.Details,
+			})
+			return
+		}
+		revReq.BaseRevision = &baseRev
+	}
 
 	pageId, err := database.GetUUID(ctx, db, revReq.PageId)
 	if err != nil {
@@ -286,9 +305,136 @@ func NewRevisionHandler(c *gin.Context) {
 	}
 	revReq.DeletedAt = deletedAt
 
+	// the API layer checks this too, but edits can come from other services
+	editor := utils.ParseEditor(c.PostForm("roles"), c.PostForm("account_created"))
+	err = requests.CheckProtection(ctx, db, pageId.String(), editor)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
 	revReq.NewContent = string(newPageBytes)
 
-	err = requests.PostRevision(ctx, db, dataDir, revReq)
+	err = requests.PostRevision(ctx, db, storage, snapshotPolicy, revReq)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		var conflict *utils.MergeConflictError
+		if errors.As(err, &conflict) {
+			c.AbortWithStatusJSON(werr.Code, gin.H{
+				"error":            werr.Details,
+				"base_revision":    conflict.BaseRevision,
+				"current_revision": conflict.CurrentRevision,
+				"merged":           conflict.Merged,
+				"conflicts":        conflict.Conflicts,
+			})
+			return
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func RevertRevisionHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	author := c.PostForm("author")
+	if author == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "author is required",
+		})
+		return
+	}
+
+	editor := utils.ParseEditor(c.PostForm("roles"), c.PostForm("account_created"))
+	err = requests.CheckProtection(ctx, db, c.Param("id"), editor)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	err = requests.RevertRevision(ctx, db, storage, snapshotPolicy, c.Param("id"), c.Param("rev"), author)
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+func RestorePageHandler(c *gin.Context) {
+	ctx := context.Background()
+	db, err := utils.GetDatabase()
+	if err != nil {
+		werr, is := wikierrors.AsWikiError(err)
+		if !is {
+			werr = wikierrors.InternalError(err)
+		}
+		c.AbortWithStatusJSON(werr.Code, gin.H{
+			"error": werr.Details,
+		})
+		return
+	}
+	defer db.Close()
+
+	var restoreReq utils.RestorePageRequest
+	err = c.Request.ParseForm()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "bad request format",
+		})
+		return
+	}
+
+	restoreReq.Slug = c.PostForm("slug")
+	if restoreReq.Slug == "" {
+		restoreReq.Slug = c.Param("id")
+	}
+	restoreReq.User = c.PostForm("user")
+	if restoreReq.User == "" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "user is required",
+		})
+		return
+	}
+
+	err = requests.RestorePage(ctx, db, storage, restoreReq)
 	if err != nil {
 		werr, is := wikierrors.AsWikiError(err)
 		if !is {
@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"wiki/filesystem"
+	"wiki/utils"
+)
+
+var storage filesystem.Storage
+
+func SetStorage(s filesystem.Storage) {
+	storage = s
+}
+
+var snapshotPolicy = utils.DefaultSnapshotPolicy
+
+func SetSnapshotPolicy(p utils.SnapshotPolicy) {
+	snapshotPolicy = p
+}
@@ -0,0 +1,90 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/filesystem"
+	"wiki/utils"
+)
+
+// GetPageBlame attributes each line of the page at revIdStr (or at its latest
+// revision when revIdStr is empty) to the revision that last changed it, by
+// replaying the page's revision diffs from the first one.
+func GetPageBlame(ctx context.Context, db *sql.DB, store filesystem.Storage, id string, revIdStr string) (*utils.PageBlame, error) {
+	pageId, err := database.GetUUID(ctx, db, id)
+	if err == sql.ErrNoRows {
+		return nil, wikierrors.PageNotFound()
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	pageDeleted, err := database.GetPageDeleted(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	if pageDeleted {
+		return nil, wikierrors.PageDeleted()
+	}
+
+	if revIdStr == "" {
+		pageInfo, err := database.GetPageInfo(ctx, db, pageId)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		if pageInfo.LastRevisionId == nil {
+			return nil, wikierrors.RevisionNotFound()
+		}
+		revIdStr = pageInfo.LastRevisionId.String()
+	}
+	target, err := getPageRevisionInfo(ctx, db, pageId, revIdStr)
+	if err != nil {
+		return nil, err
+	}
+
+	revs, err := database.GetPageRevisionsInfo(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+
+	var lines []utils.BlameSource
+	last := -1
+	for i, rev := range revs {
+		revDiff, err := filesystem.GetRevisionContent(ctx, store, pageId, *rev.UUID)
+		if err != nil {
+			return nil, wikierrors.FilesystemError(err)
+		}
+		lines, err = utils.ApplyBlameDiff(lines, revDiff, i)
+		if err != nil {
+			return nil, wikierrors.InternalError(err)
+		}
+		if *rev.UUID == *target.UUID {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return nil, wikierrors.RevisionNotFound()
+	}
+
+	blame := &utils.PageBlame{
+		PageId:   pageId,
+		Revision: *target.UUID,
+		Lines:    make([]utils.BlameLine, len(lines)),
+	}
+	for i, l := range lines {
+		rev := revs[l.Rev]
+		blame.Lines[i] = utils.BlameLine{
+			Line:     i + 1,
+			Content:  strings.TrimSuffix(l.Content, "\n"),
+			Revision: *rev.UUID,
+			DateTime: *rev.DateTime,
+		}
+		if rev.Author != nil {
+			blame.Lines[i].Author = *rev.Author
+		}
+	}
+	return blame, nil
+}
@@ -0,0 +1,198 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// MaxChangesCount is the most recent changes returned at once.
+const MaxChangesCount = 500
+
+// ChangeFilter narrows the recent changes, with the values given in the
+// query string. Empty fields don't filter.
+type ChangeFilter struct {
+	// Since and Until are RFC 3339 times or dates; Until is exclusive
+	Since string
+	Until string
+	// Category is a category's slug path; pages in its subcategories count
+	Category string
+	// Page is a page's slug or uuid
+	Page      string
+	Namespace string
+	Author    string
+	// Minor is "true" for only minor edits, "false" for none
+	Minor string
+	// Types is a comma-separated list of change types
+	Types  string
+	Cursor string
+	Count  int
+	// DiffStats counts the lines each change added and removed
+	DiffStats bool
+}
+
+// parseTimeOrDate reads an RFC 3339 time or a date (midnight UTC), as
+// taken in query strings and forms.
+func parseTimeOrDate(s string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		t, err = time.Parse("2006-01-02", s)
+	}
+	// timestamps in the database have no time zone and are UTC
+	return t.UTC(), err == nil
+}
+
+// GetRecentChanges lists the revisions of all pages, newest first. Pages
+// that have been deleted only show their deletion. The category filter
+// goes by the categories pages are in now.
+func GetRecentChanges(ctx context.Context, db *sql.DB, store filesystem.Storage, filter ChangeFilter) (utils.RecentChanges, error) {
+	var since, until *time.Time
+	if filter.Since != "" {
+		t, ok := parseTimeOrDate(filter.Since)
+		if !ok {
+			return utils.RecentChanges{}, wikierrors.InvalidChangeFilter("since must be an RFC 3339 time or a date")
+		}
+		since = &t
+	}
+	if filter.Until != "" {
+		t, ok := parseTimeOrDate(filter.Until)
+		if !ok {
+			return utils.RecentChanges{}, wikierrors.InvalidChangeFilter("until must be an RFC 3339 time or a date")
+		}
+		until = &t
+	}
+
+	var categoryIds []int
+	if filter.Category != "" {
+		ids, err := database.GetDescendantCategoryIDs(ctx, db, filter.Category)
+		if err != nil {
+			return utils.RecentChanges{}, err
+		}
+		categoryIds = ids
+	}
+
+	var pageId *uuid.UUID
+	if filter.Page != "" {
+		id, err := resolvePageId(ctx, db, filter.Page)
+		if err != nil {
+			return utils.RecentChanges{}, err
+		}
+		pageId = &id
+	}
+
+	switch filter.Namespace {
+	case "", utils.NamespaceMain, utils.NamespaceTemplate:
+	default:
+		return utils.RecentChanges{}, wikierrors.InvalidChangeFilter("namespace must be main or template")
+	}
+
+	var minor *bool
+	if filter.Minor != "" {
+		m, err := strconv.ParseBool(filter.Minor)
+		if err != nil {
+			return utils.RecentChanges{}, wikierrors.InvalidChangeFilter("minor must be true or false")
+		}
+		minor = &m
+	}
+
+	types := []string{utils.ChangeCreate, utils.ChangeEdit, utils.ChangeDelete}
+	if filter.Types != "" {
+		types = strings.Split(filter.Types, ",")
+		for _, t := range types {
+			if t != utils.ChangeCreate && t != utils.ChangeEdit && t != utils.ChangeDelete {
+				return utils.RecentChanges{}, wikierrors.InvalidChangeFilter("type must be a list of create, edit and delete")
+			}
+		}
+	}
+
+	var cursor *utils.ChangeCursor
+	if filter.Cursor != "" {
+		c, err := utils.ParseChangeCursor(filter.Cursor)
+		if err != nil {
+			return utils.RecentChanges{}, wikierrors.InvalidChangeFilter(err.Error())
+		}
+		cursor = &c
+	}
+	var cursorTime *time.Time
+	var cursorId *string
+	if cursor != nil {
+		id := cursor.UUID.String()
+		cursorTime, cursorId = &cursor.DateTime, &id
+	}
+
+	count := min(max(filter.Count, 1), MaxChangesCount)
+
+	// one more than asked for, to know if there's a next page
+	rows, err := db.QueryContext(ctx, `
+		SELECT uuid, page_id, date_time, author, slug, name, change_type, summary, minor, reverted_from
+		FROM (
+			SELECT r.uuid, r.page_id, r.date_time, r.author, r.slug, r.name, r.summary, r.minor, r.reverted_from,
+				CASE
+					WHEN r.deleted_at IS NOT NULL THEN 'delete'
+					WHEN NOT EXISTS (SELECT 1 FROM revisions e WHERE e.page_id = r.page_id AND e.date_time < r.date_time) THEN 'create'
+					ELSE 'edit'
+				END AS change_type
+			FROM revisions r
+			JOIN pages p ON p.uuid = r.page_id
+			WHERE (p.deleted_at IS NULL OR r.uuid = p.last_revision_id)
+			AND ($1::timestamp IS NULL OR r.date_time >= $1::timestamp)
+			AND ($2::timestamp IS NULL OR r.date_time < $2::timestamp)
+			AND (NOT $3 OR r.page_id IN (SELECT page_id FROM page_categories WHERE category = ANY($4)))
+			AND ($5 = '' OR ($5 = 'template') = (r.slug LIKE 'template-%'))
+			AND ($6 = '' OR r.author = $6)
+			AND ($7::boolean IS NULL OR r.minor = $7::boolean)
+			AND ($8::timestamp IS NULL OR (r.date_time, r.uuid) < ($8::timestamp, $9::uuid))
+			AND ($12::uuid IS NULL OR r.page_id = $12::uuid)
+		) changes
+		WHERE change_type = ANY($10)
+		ORDER BY date_time DESC, uuid DESC
+		LIMIT $11;
+	`, since, until, filter.Category != "", pq.Array(categoryIds), filter.Namespace, filter.Author,
+		minor, cursorTime, cursorId, pq.Array(types), count+1, pageId)
+	if err != nil {
+		return utils.RecentChanges{}, wikierrors.DatabaseError(err)
+	}
+	defer rows.Close()
+
+	changes := []utils.Change{}
+	for rows.Next() {
+		var change utils.Change
+		err = rows.Scan(&change.UUID, &change.PageId, &change.DateTime, &change.Author, &change.Slug, &change.Name,
+			&change.Type, &change.Summary, &change.Minor, &change.RevertedFrom)
+		if err != nil {
+			return utils.RecentChanges{}, wikierrors.DatabaseError(err)
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return utils.RecentChanges{}, wikierrors.DatabaseError(err)
+	}
+
+	if filter.DiffStats {
+		for i, change := range changes {
+			diff, err := filesystem.GetRevisionContent(ctx, store, change.PageId, change.UUID)
+			if err != nil {
+				return utils.RecentChanges{}, wikierrors.FilesystemError(err)
+			}
+			stats := utils.CountDiffLines(diff)
+			changes[i].DiffStats = &stats
+		}
+	}
+
+	result := utils.RecentChanges{Changes: changes}
+	if len(changes) > count {
+		result.Changes = slices.Clip(changes[:count])
+		result.NextCursor = utils.NewChangeCursor(result.Changes[count-1]).String()
+	}
+	return result, nil
+}
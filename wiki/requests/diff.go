@@ -0,0 +1,149 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// diffSides is the content of a page at the two revisions being compared.
+// from is nil when the comparison starts from an empty page.
+type diffSides struct {
+	pageId      uuid.UUID
+	slug        string
+	from        *uuid.UUID
+	to          uuid.UUID
+	fromContent string
+	toContent   string
+}
+
+// GetRevisionDiff compares the page at two of its revisions, which needn't be
+// adjacent. An empty to means the page's latest revision, and an empty from
+// means the revision before to.
+func GetRevisionDiff(ctx context.Context, db *sql.DB, store filesystem.Storage, id string, from string, to string, contextLines int) (*utils.RevisionDiff, error) {
+	sides, err := getDiffSides(ctx, db, store, id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	hunks, err := utils.DiffHunks(sides.fromContent, sides.toContent, contextLines)
+	if err != nil {
+		return nil, wikierrors.InternalError(err)
+	}
+	return &utils.RevisionDiff{
+		PageId: sides.pageId,
+		From:   sides.from,
+		To:     sides.to,
+		Hunks:  hunks,
+	}, nil
+}
+
+// GetUnifiedRevisionDiff is GetRevisionDiff as unified diff text.
+func GetUnifiedRevisionDiff(ctx context.Context, db *sql.DB, store filesystem.Storage, id string, from string, to string, contextLines int) (string, error) {
+	sides, err := getDiffSides(ctx, db, store, id, from, to)
+	if err != nil {
+		return "", err
+	}
+	fromLabel := sides.slug + "@empty"
+	if sides.from != nil {
+		fromLabel = sides.slug + "@" + sides.from.String()
+	}
+	toLabel := sides.slug + "@" + sides.to.String()
+	diff, err := utils.UnifiedRevisionDiff(fromLabel, toLabel, sides.fromContent, sides.toContent, contextLines)
+	if err != nil {
+		return "", wikierrors.InternalError(err)
+	}
+	return diff, nil
+}
+
+func getDiffSides(ctx context.Context, db *sql.DB, store filesystem.Storage, id string, from string, to string) (*diffSides, error) {
+	pageId, err := database.GetUUID(ctx, db, id)
+	if err == sql.ErrNoRows {
+		return nil, wikierrors.PageNotFound()
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	pageInfo, err := database.GetPageInfo(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	pageDeleted, err := database.GetPageDeleted(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	if pageDeleted {
+		return nil, wikierrors.PageDeleted()
+	}
+
+	sides := &diffSides{pageId: pageId, slug: pageInfo.Slug}
+
+	if to == "" {
+		if pageInfo.LastRevisionId == nil {
+			return nil, wikierrors.RevisionNotFound()
+		}
+		to = pageInfo.LastRevisionId.String()
+	}
+	toRev, err := getPageRevisionInfo(ctx, db, pageId, to)
+	if err != nil {
+		return nil, err
+	}
+	sides.to = *toRev.UUID
+
+	if from == "" {
+		var prevId uuid.UUID
+		err = db.QueryRowContext(ctx, `
+			SELECT uuid FROM revisions
+			WHERE page_id=$1 AND date_time < $2
+			ORDER BY date_time DESC LIMIT 1;
+		`, pageId, toRev.DateTime).Scan(&prevId)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		if err == nil {
+			sides.from = &prevId
+		}
+	} else {
+		fromRev, err := getPageRevisionInfo(ctx, db, pageId, from)
+		if err != nil {
+			return nil, err
+		}
+		sides.from = fromRev.UUID
+	}
+
+	if sides.from != nil {
+		sides.fromContent, err = utils.GetContentAtRevision(ctx, db, store, pageId, *sides.from)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sides.toContent, err = utils.GetContentAtRevision(ctx, db, store, pageId, sides.to)
+	if err != nil {
+		return nil, err
+	}
+	return sides, nil
+}
+
+// getPageRevisionInfo looks up a revision, which must belong to pageId.
+func getPageRevisionInfo(ctx context.Context, db *sql.DB, pageId uuid.UUID, revIdStr string) (*database.RevInfo, error) {
+	revId, err := uuid.Parse(revIdStr)
+	if err != nil {
+		return nil, wikierrors.InvalidID(err)
+	}
+	revInfo, err := database.GetRevisionInfo(ctx, db, revId)
+	if err == sql.ErrNoRows {
+		return nil, wikierrors.RevisionNotFound()
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	if revInfo.PageId == nil || *revInfo.PageId != pageId {
+		return nil, wikierrors.RevisionNotFound()
+	}
+	return revInfo, nil
+}
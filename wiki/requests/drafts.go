@@ -0,0 +1,100 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"wiki/database"
+	wikierrors "wiki/errors"
+
+	"github.com/google/uuid"
+)
+
+// GetDrafts lists a user's drafts.
+func GetDrafts(ctx context.Context, db *sql.DB, user string) ([]database.Draft, error) {
+	if user == "" {
+		return nil, wikierrors.UserRequired()
+	}
+	drafts, err := database.GetDrafts(ctx, db, user)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return drafts, nil
+}
+
+// GetDraft returns a user's draft of a page.
+func GetDraft(ctx context.Context, db *sql.DB, user string, id string) (*database.Draft, error) {
+	if user == "" {
+		return nil, wikierrors.UserRequired()
+	}
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	draft, err := database.GetDraft(ctx, db, user, pageId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, wikierrors.DraftNotFound()
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return draft, nil
+}
+
+// SaveDraft creates or replaces a user's draft of a page. baseRevision is
+// the revision the edit started from, if any, so that saving the draft
+// later merges in what changed since.
+func SaveDraft(ctx context.Context, db *sql.DB, user string, id string, baseRevision string, content string) (*database.Draft, error) {
+	if user == "" {
+		return nil, wikierrors.UserRequired()
+	}
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := database.GetPageDeleted(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	if deleted {
+		return nil, wikierrors.PageDeleted()
+	}
+
+	var baseRev *uuid.UUID
+	if baseRevision != "" {
+		rev, err := uuid.Parse(baseRevision)
+		if err != nil {
+			return nil, wikierrors.InvalidID(err)
+		}
+		baseRev = &rev
+	}
+
+	err = database.SaveDraft(ctx, db, user, pageId, baseRev, content)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	draft, err := database.GetDraft(ctx, db, user, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return draft, nil
+}
+
+// DeleteDraft discards a user's draft of a page.
+func DeleteDraft(ctx context.Context, db *sql.DB, user string, id string) error {
+	if user == "" {
+		return wikierrors.UserRequired()
+	}
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := database.DeleteDraft(ctx, db, user, pageId)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if !deleted {
+		return wikierrors.DraftNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/filesystem"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// exportedPage is a page's state as the export replays its history.
+type exportedPage struct {
+	content string
+	path    string
+	name    string
+	created bool
+}
+
+// ExportHistory writes the history of the wiki, or of one page when id is
+// set, to w as a git fast-import stream on refs/heads/main. Every revision
+// becomes a commit by its author at its time, writing the page's content to
+// pages/<slug>.md; renames move the file and deletions remove it.
+//
+// The page is looked up before anything is written, so a bad id is a normal
+// error. An error part way through leaves the stream without its closing
+// "done", which git fast-import rejects.
+func ExportHistory(ctx context.Context, db *sql.DB, store filesystem.Storage, w io.Writer, id string) error {
+	query := `
+		SELECT uuid, page_id, date_time, author, slug, name, deleted_at, reverted_from
+		FROM revisions ORDER BY date_time, uuid;`
+	var args []any
+	if id != "" {
+		pageId, err := database.GetUUID(ctx, db, id)
+		if err == sql.ErrNoRows {
+			return wikierrors.PageNotFound()
+		}
+		if err != nil {
+			return wikierrors.DatabaseError(err)
+		}
+		query = `
+			SELECT uuid, page_id, date_time, author, slug, name, deleted_at, reverted_from
+			FROM revisions WHERE page_id=$1 ORDER BY date_time, uuid;`
+		args = append(args, pageId)
+	}
+
+	var revs []database.RevInfo
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	for rows.Next() {
+		var rev database.RevInfo
+		err = rows.Scan(&rev.UUID, &rev.PageId, &rev.DateTime, &rev.Author, &rev.Slug, &rev.Name, &rev.DeletedAt, &rev.RevertedFrom)
+		if err != nil {
+			rows.Close()
+			return wikierrors.DatabaseError(err)
+		}
+		revs = append(revs, rev)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+
+	out := utils.NewFastImportWriter(w, "refs/heads/main")
+	pages := make(map[uuid.UUID]*exportedPage)
+	for _, rev := range revs {
+		page, ok := pages[*rev.PageId]
+		if !ok {
+			page = &exportedPage{}
+			pages[*rev.PageId] = page
+		}
+
+		revDiff, err := filesystem.GetRevisionContent(ctx, store, *rev.PageId, *rev.UUID)
+		if err != nil {
+			return wikierrors.FilesystemError(fmt.Errorf("revision %s: %w", rev.UUID, err))
+		}
+		page.content, err = utils.ApplyRevisionDiff(page.content, revDiff)
+		if err != nil {
+			return wikierrors.InternalError(fmt.Errorf("revision %s: %w", rev.UUID, err))
+		}
+
+		path := ""
+		if rev.DeletedAt == nil {
+			path = "pages/" + rev.Slug + ".md"
+		}
+		commit := utils.FastImportCommit{
+			When:    *rev.DateTime,
+			Message: exportMessage(page, rev, path),
+		}
+		if rev.Author != nil {
+			commit.Author = *rev.Author
+		}
+		if page.path != "" && page.path != path {
+			commit.Deletes = append(commit.Deletes, page.path)
+		}
+		if path != "" {
+			commit.Files = append(commit.Files, utils.FastImportFile{Path: path, Content: page.content})
+		}
+		err = out.Commit(commit)
+		if err != nil {
+			return err
+		}
+		page.path, page.name, page.created = path, rev.Name, true
+	}
+	return out.Close()
+}
+
+// exportMessage describes what a revision did to its page, with the IDs it
+// came from as trailers.
+func exportMessage(page *exportedPage, rev database.RevInfo, path string) string {
+	var subject string
+	switch {
+	case !page.created:
+		subject = "Create " + rev.Name
+	case path == "" && page.path != "":
+		subject = "Delete " + rev.Name
+	case path != "" && page.path == "":
+		subject = "Restore " + rev.Name
+	case rev.RevertedFrom != nil:
+		subject = fmt.Sprintf("Revert %s to %s", rev.Name, rev.RevertedFrom)
+	case page.path != path:
+		subject = fmt.Sprintf("Rename %s to %s", page.name, rev.Name)
+	default:
+		subject = "Update " + rev.Name
+	}
+	return fmt.Sprintf("%s\n\nPage: %s\nRevision: %s\n", subject, rev.PageId, rev.UUID)
+}
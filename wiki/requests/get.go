@@ -13,7 +13,7 @@ import (
 	"github.com/lib/pq"
 )
 
-func GetPage(ctx context.Context, db *sql.DB, dataDir string, id string) (utils.Page, error) {
+func GetPage(ctx context.Context, db *sql.DB, store filesystem.Storage, id string) (utils.Page, error) {
 	var page utils.Page
 	var info *database.PageInfo
 	var content string
@@ -42,7 +42,7 @@ func GetPage(ctx context.Context, db *sql.DB, dataDir string, id string) (utils.
 		return utils.Page{}, wikierrors.PageDeleted()
 	}
 
-	content, err = filesystem.GetPageContent(ctx, db, dataDir, pageId)
+	content, err = filesystem.GetPageContent(ctx, store, pageId)
 	if err != nil {
 		return utils.Page{}, wikierrors.FilesystemError(err)
 	}
@@ -62,11 +62,23 @@ func GetPage(ctx context.Context, db *sql.DB, dataDir string, id string) (utils.
 	page = utils.Page{UUID: info.UUID, Slug: info.Slug, Name: info.Name, ArchiveDate: info.ArchiveDate,
 		LastEdit: lastRev.UUID, LastEditTime: lastRev.DateTime,
 		Content: content}
+	page.MissingLinks, err = database.GetMissingLinks(ctx, db, pageId)
+	if err != nil {
+		return utils.Page{}, wikierrors.DatabaseError(err)
+	}
+	page.Protection, err = database.GetProtection(ctx, db, pageId)
+	if err != nil && err != sql.ErrNoRows {
+		return utils.Page{}, wikierrors.DatabaseError(err)
+	}
+	// asked for by an old slug or an alias
+	if uuid.Validate(id) != nil && id != info.Slug {
+		page.RedirectedFrom = id
+	}
 
 	return page, nil
 }
 
-func GetPages(ctx context.Context, db *sql.DB, dataDir string, ind int, count int) ([]utils.PageInfoPrev, error) {
+func GetPages(ctx context.Context, db *sql.DB, store filesystem.Storage, ind int, count int) ([]utils.PageInfoPrev, error) {
 	var pagesCount int
 	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pages WHERE deleted_at IS NULL").Scan(&pagesCount)
 	if err != nil {
@@ -92,7 +104,7 @@ func GetPages(ctx context.Context, db *sql.DB, dataDir string, ind int, count in
 	for uuids.Next() {
 		var id uuid.UUID
 		uuids.Scan(&id)
-		pageInfo, err := utils.GetPageInfoPreview(ctx, db, dataDir, id)
+		pageInfo, err := utils.GetPageInfoPreview(ctx, db, store, id)
 		if err != nil {
 			return nil, wikierrors.DatabaseFilesystemError(err)
 		}
@@ -105,14 +117,14 @@ func GetPages(ctx context.Context, db *sql.DB, dataDir string, ind int, count in
 	return pages, nil
 }
 
-func GetPagesBySlugs(ctx context.Context, db *sql.DB, dataDir string, slugList []string) []utils.PageInfoPrev {
+func GetPagesBySlugs(ctx context.Context, db *sql.DB, store filesystem.Storage, slugList []string) []utils.PageInfoPrev {
 	var pages []utils.PageInfoPrev
 	for _, slug := range slugList {
 		uuid, err := database.GetUUID(ctx, db, slug)
 		if err != nil {
 			continue
 		}
-		pageInfoPrev, err := utils.GetPageInfoPreview(ctx, db, dataDir, uuid)
+		pageInfoPrev, err := utils.GetPageInfoPreview(ctx, db, store, uuid)
 		if err != nil {
 			continue
 		}
@@ -123,7 +135,7 @@ func GetPagesBySlugs(ctx context.Context, db *sql.DB, dataDir string, slugList [
 	return pages
 }
 
-func GetPagesCategory(ctx context.Context, db *sql.DB, dataDir string,
+func GetPagesCategory(ctx context.Context, db *sql.DB, store filesystem.Storage,
 	catSlug string, ind int, count int, exact bool) ([]utils.PageInfoPrev, error) {
 
 	var categoryIds []int
@@ -178,7 +190,7 @@ func GetPagesCategory(ctx context.Context, db *sql.DB, dataDir string,
 		if err != nil {
 			return nil, wikierrors.DatabaseError(err)
 		}
-		page, err := utils.GetPageInfoPreview(ctx, db, dataDir, pageUUID)
+		page, err := utils.GetPageInfoPreview(ctx, db, store, pageUUID)
 		if err != nil {
 			return nil, wikierrors.DatabaseError(err)
 		}
@@ -190,7 +202,7 @@ func GetPagesCategory(ctx context.Context, db *sql.DB, dataDir string,
 	return pages, nil
 }
 
-func GetRevision(ctx context.Context, db *sql.DB, dataDir string, revId string) (utils.Revision, error) {
+func GetRevision(ctx context.Context, db *sql.DB, store filesystem.Storage, revId string) (utils.Revision, error) {
 	var err error
 	var rev = utils.Revision{}
 
@@ -236,8 +248,11 @@ func GetRevision(ctx context.Context, db *sql.DB, dataDir string, revId string)
 	rev.Name = revInfo.Name
 	rev.ArchiveDate = revInfo.ArchiveDate
 	rev.DeletedAt = revInfo.DeletedAt
+	rev.RevertedFrom = revInfo.RevertedFrom
+	rev.Summary = revInfo.Summary
+	rev.Minor = revInfo.Minor
 
-	rev.Content, err = utils.GetContentAtRevision(ctx, db, dataDir, rev.PageId, rev.UUID)
+	rev.Content, err = utils.GetContentAtRevision(ctx, db, store, rev.PageId, rev.UUID)
 	if err != nil {
 		// GetContentAtRevision returns wikierror
 		return utils.Revision{}, err
@@ -332,3 +347,43 @@ func GetRevisionsByAuthor(ctx context.Context, db *sql.DB, author string, ind in
 
 	return revs, nil
 }
+
+// GetDeletedPages lists deleted pages, most recently deleted first, with the
+// author of the revision that deleted each one.
+func GetDeletedPages(ctx context.Context, db *sql.DB, ind int, count int) ([]utils.DeletedPage, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT p.uuid, p.slug, p.name, p.deleted_at,
+			(SELECT r.author FROM revisions r
+			 WHERE r.page_id = p.uuid AND r.deleted_at IS NOT NULL
+			 ORDER BY r.date_time DESC
+			 LIMIT 1)
+		FROM pages p
+		WHERE p.deleted_at IS NOT NULL
+		ORDER BY p.deleted_at DESC
+		LIMIT $1
+		OFFSET $2;
+	`, count, ind)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	defer rows.Close()
+
+	pages := []utils.DeletedPage{}
+	for rows.Next() {
+		var page utils.DeletedPage
+		var deletedBy *string
+		err = rows.Scan(&page.UUID, &page.Slug, &page.Name, &page.DeletedAt, &deletedBy)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		if deletedBy != nil {
+			page.DeletedBy = *deletedBy
+		}
+		pages = append(pages, page)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+
+	return pages, nil
+}
@@ -0,0 +1,21 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"wiki/database"
+	wikierrors "wiki/errors"
+)
+
+// GetBacklinks lists the pages that link to a page ("what links here").
+func GetBacklinks(ctx context.Context, db *sql.DB, id string) ([]database.PageLink, error) {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	links, err := database.GetBacklinks(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return links, nil
+}
@@ -3,9 +3,8 @@ package requests
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 	"wiki/database"
 	wikierrors "wiki/errors"
@@ -16,61 +15,12 @@ import (
 	"github.com/google/uuid"
 )
 
-// revertRenames attempts to rename revision and snapshot files back from newSlug
-// to oldSlug for every revision/snapshot belonging to pageId. Errors from
-// individual renames are silently ignored because a file may not yet have been
-// renamed (i.e. the failure occurred before that point in UpdatePage).
-func revertRenames(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID, oldSlug, newSlug string) {
-	if oldSlug == newSlug {
-		return
-	}
-
-	revRows, err := db.QueryContext(ctx, `SELECT uuid FROM revisions WHERE page_id=$1;`, pageId)
-	if err == nil {
-		defer revRows.Close()
-		for revRows.Next() {
-			var revId uuid.UUID
-			if revRows.Scan(&revId) == nil {
-				os.Rename(
-					filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", newSlug, revId)),
-					filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", oldSlug, revId)),
-				)
-			}
-		}
-	}
-
-	snapRows, err := db.QueryContext(ctx, `SELECT uuid FROM snapshots WHERE page=$1;`, pageId)
-	if err == nil {
-		defer snapRows.Close()
-		for snapRows.Next() {
-			var snapId uuid.UUID
-			if snapRows.Scan(&snapId) == nil {
-				os.Rename(
-					filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", newSlug, snapId)),
-					filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", oldSlug, snapId)),
-				)
-			}
-		}
-	}
-}
-
-func cleanupRevisionFailure(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID, revId uuid.UUID, originalSlug, newSlug, pageContent string, prevLastRevision *uuid.UUID) {
-	revertRenames(ctx, db, dataDir, pageId, originalSlug, newSlug)
-
-	revNewPath := filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", newSlug, revId))
-	revOldPath := filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", originalSlug, revId))
-	_ = os.Remove(revNewPath)
-	if originalSlug != newSlug {
-		_ = os.Remove(revOldPath)
-	}
-
-	originalPagePath := filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", originalSlug))
-	newPagePath := filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", newSlug))
-	if originalSlug != newSlug {
-		_ = os.Rename(newPagePath, originalPagePath)
-		_ = os.Remove(newPagePath)
-	}
-	_ = os.WriteFile(originalPagePath, []byte(pageContent), 0644)
+// cleanupRevisionFailure undoes a revision whose page update failed: the
+// revision file and row are removed and the page file and last_revision_id
+// are put back.
+func cleanupRevisionFailure(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId uuid.UUID, revId uuid.UUID, pageContent string, prevLastRevision *uuid.UUID) {
+	_ = store.DeleteRevision(ctx, pageId, revId)
+	_ = store.PutPage(ctx, pageId, pageContent)
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -89,7 +39,7 @@ func cleanupRevisionFailure(ctx context.Context, db *sql.DB, dataDir string, pag
 	_ = tx.Commit()
 }
 
-func DeletePage(ctx context.Context, db *sql.DB, dataDir string, delReq utils.DeletePageRequest) error {
+func DeletePage(ctx context.Context, db *sql.DB, store filesystem.Storage, delReq utils.DeletePageRequest) error {
 	pageUUID, err := database.GetUUID(ctx, db, delReq.Slug)
 	if err != nil {
 		return wikierrors.DatabaseError(err)
@@ -128,32 +78,30 @@ func DeletePage(ctx context.Context, db *sql.DB, dataDir string, delReq utils.De
 
 	var revId uuid.UUID
 	err = tx.QueryRowContext(ctx, `
-			INSERT INTO revisions (page_id, author, slug, name, archive_date, deleted_at)
-			VALUES ($1, $2, $3, $4, $5, $6)
+			INSERT INTO revisions (page_id, author, slug, name, archive_date, deleted_at, summary)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
 			RETURNING uuid;
-			`, pageInfo.UUID, delReq.User, pageInfo.Slug, pageInfo.Name, pageInfo.ArchiveDate, deletedAt).
+			`, pageInfo.UUID, delReq.User, pageInfo.Slug, pageInfo.Name, pageInfo.ArchiveDate, deletedAt, utils.SummaryDeleted).
 		Scan(&revId)
 	if err != nil {
 		return wikierrors.DatabaseError(err)
 	}
+	err = database.NotifyWatchers(ctx, tx, pageInfo.UUID, &revId, database.NotifyDelete, delReq.User, utils.SummaryDeleted, nil)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
 
 	// Create diff with empty change
-	pageContent, err := filesystem.GetPageContent(ctx, db, dataDir, pageUUID)
+	pageContent, err := filesystem.GetPageContent(ctx, store, pageUUID)
 	if err != nil {
 		tx.Rollback()
 		return wikierrors.FilesystemError(err)
 	}
 	// Create a diff showing no changes (old == new)
-	pageFilename := fmt.Sprintf("%s.md", pageInfo.Slug)
+	pageFilename := filesystem.GetPageFilename(pageInfo.UUID)
 	diff := udiff.Unified(pageFilename, pageFilename, pageContent, pageContent)
 	// Write the revision file
-	filename := fmt.Sprintf("%s_%s.txt", pageInfo.Slug, revId)
-	err = os.MkdirAll(filepath.Join(dataDir, "revisions"), 0755)
-	if err != nil {
-		tx.Rollback()
-		return wikierrors.FilesystemError(err)
-	}
-	err = os.WriteFile(filepath.Join(dataDir, "revisions", filename), []byte(diff), 0644)
+	err = store.PutRevision(ctx, pageInfo.UUID, revId, diff)
 	if err != nil {
 		tx.Rollback()
 		return wikierrors.FilesystemError(err)
@@ -161,90 +109,272 @@ func DeletePage(ctx context.Context, db *sql.DB, dataDir string, delReq utils.De
 
 	err = tx.Commit()
 	if err != nil {
-		os.Remove(filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", pageInfo.Slug, revId)))
+		store.DeleteRevision(ctx, pageInfo.UUID, revId)
 		return wikierrors.DatabaseError(err)
 	}
 	return nil
 }
 
-func PostRevision(ctx context.Context, db *sql.DB, dataDir string, revReq utils.RevisionRequest) error {
+// mergeWithCurrent three-way merges content that was written against baseRev
+// into the page's current revision. Overlapping edits are returned as a
+// RevisionConflict wrapping a MergeConflictError with the conflicting hunks.
+func mergeWithCurrent(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId, baseRev, currentRev uuid.UUID, content string) (string, error) {
+	baseInfo, err := database.GetRevisionInfo(ctx, db, baseRev)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", wikierrors.RevisionNotFound()
+	}
+	if err != nil {
+		return "", wikierrors.DatabaseError(err)
+	}
+	if baseInfo.PageId == nil || *baseInfo.PageId != pageId {
+		return "", wikierrors.RevisionNotFound()
+	}
+
+	baseContent, err := utils.GetContentAtRevision(ctx, db, store, pageId, baseRev)
+	if err != nil {
+		return "", err
+	}
+	currentContent, err := utils.GetContentAtRevision(ctx, db, store, pageId, currentRev)
+	if err != nil {
+		return "", err
+	}
+
+	merged, conflicts := utils.MergeContent(baseContent, currentContent, content)
+	if len(conflicts) > 0 {
+		return "", wikierrors.RevisionConflict(&utils.MergeConflictError{
+			BaseRevision:    baseRev,
+			CurrentRevision: currentRev,
+			Merged:          merged,
+			Conflicts:       conflicts,
+		})
+	}
+	return merged, nil
+}
+
+func PostRevision(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, revReq utils.RevisionRequest) error {
 	var err error
 
 	pageId, err := database.GetUUID(ctx, db, revReq.PageId)
 	if err != nil {
 		return wikierrors.DatabaseError(err)
 	}
-	var pageSlug string
+
+	revisionTx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer revisionTx.Rollback()
+
+	// Lock the page row so concurrent edits are applied one at a time against
+	// a stable last_revision_id.
 	var prevLastRevision *uuid.UUID
-	err = db.QueryRowContext(ctx, `
-		SELECT slug, last_revision_id FROM pages WHERE uuid=$1;
-	`, pageId).Scan(&pageSlug, &prevLastRevision)
+	err = revisionTx.QueryRowContext(ctx, `
+		SELECT last_revision_id FROM pages WHERE uuid=$1 FOR UPDATE;
+	`, pageId).Scan(&prevLastRevision)
 	if err != nil {
 		return wikierrors.DatabaseError(err)
 	}
-	originalSlug := pageSlug
-	pageContent, err := filesystem.GetPageContent(ctx, db, dataDir, pageId)
+	pageContent, err := filesystem.GetPageContent(ctx, store, pageId)
 	if err != nil {
 		return wikierrors.FilesystemError(err)
 	}
 
-	revisionTx, err := db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
+	// The edit was made against an older revision: merge it into the current one.
+	if revReq.BaseRevision != nil && prevLastRevision != nil && *revReq.BaseRevision != *prevLastRevision {
+		merged, err := mergeWithCurrent(ctx, db, store, pageId, *revReq.BaseRevision, *prevLastRevision, revReq.NewContent)
+		if err != nil {
+			return err
+		}
+		revReq.NewContent = merged
 	}
-	defer revisionTx.Rollback()
-	revId, err := utils.CreateRevision(ctx, db, revisionTx, dataDir, revReq)
+
+	revId, err := utils.CreateRevision(ctx, db, revisionTx, store, revReq)
 	if err != nil {
 		return wikierrors.DatabaseFilesystemError(err)
 	}
 	err = revisionTx.Commit()
 	if err != nil {
-		os.Remove(filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", revReq.Slug, revId)))
+		store.DeleteRevision(ctx, pageId, revId)
 		revisionTx.Rollback()
 		return err
 	}
 
-	// cleanupSlug is the slug the page will have after a successful UpdatePage.
-	// Set it before the transaction commits so snapshot cleanup uses the right name.
-	cleanupSlug := revReq.Slug
-
 	pageTx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return wikierrors.DatabaseError(err)
 	}
 	defer pageTx.Rollback()
-	err = utils.UpdatePage(ctx, db, pageTx, dataDir, revId)
+	err = utils.UpdatePage(ctx, db, pageTx, store, revId)
 	if err != nil {
-		cleanupRevisionFailure(ctx, db, dataDir, pageId, revId, originalSlug, revReq.Slug, pageContent, prevLastRevision)
+		cleanupRevisionFailure(ctx, db, store, pageId, revId, pageContent, prevLastRevision)
 		return wikierrors.DatabaseFilesystemError(err)
 	}
 	err = pageTx.Commit()
 	if err != nil {
-		cleanupRevisionFailure(ctx, db, dataDir, pageId, revId, originalSlug, revReq.Slug, pageContent, prevLastRevision)
+		cleanupRevisionFailure(ctx, db, store, pageId, revId, pageContent, prevLastRevision)
 		return wikierrors.DatabaseFilesystemError(err)
 	}
 
-	missingRevs, err := database.GetMissingRevisions(ctx, db, revId)
+	snapshotDue, err := utils.SnapshotDue(ctx, db, store, policy, pageId, revId)
 	if err != nil {
-		return wikierrors.DatabaseError(err)
+		return wikierrors.DatabaseFilesystemError(err)
 	}
-	if len(missingRevs) >= 10 {
+	if snapshotDue {
 		snapTx, err := db.BeginTx(ctx, nil)
 		if err != nil {
 			return wikierrors.DatabaseError(err)
 		}
 		defer snapTx.Rollback()
-		snapId, err := utils.CreateSnapshot(ctx, db, snapTx, dataDir, pageId, revId)
+		snapId, err := utils.CreateSnapshot(ctx, db, snapTx, store, pageId, revId)
 		if err != nil {
-			os.Remove(filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", cleanupSlug, snapId)))
+			store.DeleteSnapshot(ctx, pageId, snapId)
 			return wikierrors.DatabaseFilesystemError(err)
 		}
 		err = snapTx.Commit()
 		if err != nil {
-			os.Remove(filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", cleanupSlug, snapId)))
+			store.DeleteSnapshot(ctx, pageId, snapId)
 			return wikierrors.DatabaseFilesystemError(err)
 		}
 	}
 
 	return nil
 }
+
+// RevertRevision records a new revision of the page that restores the content,
+// slug, name and archive date it had at revId.
+func RevertRevision(ctx context.Context, db *sql.DB, store filesystem.Storage, policy utils.SnapshotPolicy, id string, revIdStr string, author string) error {
+	pageId, err := database.GetUUID(ctx, db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return wikierrors.PageNotFound()
+	}
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	revId, err := uuid.Parse(revIdStr)
+	if err != nil {
+		return wikierrors.InvalidID(err)
+	}
+
+	pageDeleted, err := database.GetPageDeleted(ctx, db, pageId)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if pageDeleted {
+		return wikierrors.PageDeleted()
+	}
+
+	revInfo, err := database.GetRevisionInfo(ctx, db, revId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return wikierrors.RevisionNotFound()
+	}
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if revInfo.PageId == nil || *revInfo.PageId != pageId {
+		return wikierrors.RevisionNotFound()
+	}
+	if revInfo.DeletedAt != nil {
+		return wikierrors.RevisionDeleted()
+	}
+
+	// the old slug may since have been taken by another page
+	var slugOwner uuid.UUID
+	err = db.QueryRowContext(ctx, `
+		SELECT uuid FROM pages WHERE slug=$1;
+	`, revInfo.Slug).Scan(&slugOwner)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return wikierrors.DatabaseError(err)
+	}
+	if err == nil && slugOwner != pageId {
+		return wikierrors.RevisionConflict(fmt.Errorf("slug %q is used by page %s", revInfo.Slug, slugOwner))
+	}
+
+	content, err := utils.GetContentAtRevision(ctx, db, store, pageId, revId)
+	if err != nil {
+		return err
+	}
+
+	return PostRevision(ctx, db, store, policy, utils.RevisionRequest{
+		PageId:       pageId.String(),
+		Author:       author,
+		Slug:         revInfo.Slug,
+		Name:         revInfo.Name,
+		ArchiveDate:  revInfo.ArchiveDate,
+		RevertedFrom: &revId,
+		Summary:      utils.RevertSummary(*revInfo.Author, *revInfo.DateTime),
+		NewContent:   content,
+	})
+}
+
+// RestorePage undeletes a page and records the restore as a new revision
+// authored by the moderator who restored it.
+func RestorePage(ctx context.Context, db *sql.DB, store filesystem.Storage, restoreReq utils.RestorePageRequest) error {
+	pageUUID, err := database.GetUUID(ctx, db, restoreReq.Slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return wikierrors.PageNotFound()
+	}
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	pageInfo, err := database.GetPageInfo(ctx, db, pageUUID)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+
+	pageDeleted, err := database.GetPageDeleted(ctx, db, pageInfo.UUID)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if !pageDeleted {
+		return wikierrors.PageNotDeleted()
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return wikierrors.InternalError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE pages
+		SET deleted_at=NULL
+		WHERE uuid=$1;
+	`, pageInfo.UUID)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+
+	var revId uuid.UUID
+	err = tx.QueryRowContext(ctx, `
+			INSERT INTO revisions (page_id, author, slug, name, archive_date, summary)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			RETURNING uuid;
+			`, pageInfo.UUID, restoreReq.User, pageInfo.Slug, pageInfo.Name, pageInfo.ArchiveDate, utils.SummaryRestored).
+		Scan(&revId)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	err = database.NotifyWatchers(ctx, tx, pageInfo.UUID, &revId, database.NotifyEdit, restoreReq.User, utils.SummaryRestored, nil)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+
+	// The content is unchanged, so the restore revision is an empty diff
+	pageContent, err := filesystem.GetPageContent(ctx, store, pageUUID)
+	if err != nil {
+		return wikierrors.FilesystemError(err)
+	}
+	pageFilename := filesystem.GetPageFilename(pageInfo.UUID)
+	diff := udiff.Unified(pageFilename, pageFilename, pageContent, pageContent)
+	err = store.PutRevision(ctx, pageInfo.UUID, revId, diff)
+	if err != nil {
+		return wikierrors.FilesystemError(err)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		store.DeleteRevision(ctx, pageInfo.UUID, revId)
+		return wikierrors.DatabaseError(err)
+	}
+	return nil
+}
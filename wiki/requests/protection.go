@@ -0,0 +1,116 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+	"unicode/utf8"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/utils"
+)
+
+// MaxProtectionReasonLength is the longest a protection reason can be, in
+// characters.
+const MaxProtectionReasonLength = 500
+
+// GetProtection returns a page's protection. Unprotected pages, including
+// ones whose protection has expired, have the level "open".
+func GetProtection(ctx context.Context, db *sql.DB, id string) (*database.Protection, error) {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	protection, err := database.GetProtection(ctx, db, pageId)
+	if errors.Is(err, sql.ErrNoRows) {
+		info, err := database.GetPageInfo(ctx, db, pageId)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		return &database.Protection{PageId: pageId, Slug: info.Slug, Name: info.Name, Level: utils.ProtectionOpen}, nil
+	}
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return protection, nil
+}
+
+// GetProtectedPages lists the pages that are protected now.
+func GetProtectedPages(ctx context.Context, db *sql.DB) ([]database.Protection, error) {
+	protected, err := database.GetProtectedPages(ctx, db)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return protected, nil
+}
+
+// SetProtection changes who can edit a page. level "open" removes its
+// protection. minAccountDays is only used by "semi". expiresAt is an
+// RFC 3339 time or a date, or empty for protection that doesn't expire.
+func SetProtection(ctx context.Context, db *sql.DB, id string, level string, minAccountDays string, expiresAt string, user string, reason string) error {
+	if user == "" {
+		return wikierrors.UserRequired()
+	}
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+
+	protection := database.Protection{PageId: pageId, Level: level, ProtectedBy: user, Reason: reason}
+	switch level {
+	case utils.ProtectionOpen:
+		err = database.RemoveProtection(ctx, db, pageId)
+		if err != nil {
+			return wikierrors.DatabaseError(err)
+		}
+		return nil
+	case utils.ProtectionSemi:
+		days, err := strconv.Atoi(minAccountDays)
+		if err != nil || days < 1 {
+			return wikierrors.InvalidProtection("min_account_days must be a whole number of days, at least 1")
+		}
+		protection.MinAccountDays = days
+	case utils.ProtectionModerator, utils.ProtectionLocked:
+	default:
+		return wikierrors.InvalidProtection("level must be open, semi, moderator or locked")
+	}
+	if utf8.RuneCountInString(reason) > MaxProtectionReasonLength {
+		return wikierrors.InvalidProtection("reason is too long")
+	}
+
+	if expiresAt != "" {
+		expires, ok := parseTimeOrDate(expiresAt)
+		if !ok {
+			return wikierrors.InvalidProtection("expires_at must be an RFC 3339 time or a date")
+		}
+		if !expires.After(time.Now()) {
+			return wikierrors.InvalidProtection("expires_at must be in the future")
+		}
+		protection.ExpiresAt = &expires
+	}
+
+	err = database.SetProtection(ctx, db, protection)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	return nil
+}
+
+// CheckProtection returns a PageProtected error if the editor can't edit
+// the page.
+func CheckProtection(ctx context.Context, db *sql.DB, id string, editor utils.Editor) error {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	protection, err := database.GetProtection(ctx, db, pageId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	return utils.CheckProtection(protection, editor, time.Now())
+}
@@ -0,0 +1,74 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"wiki/database"
+	wikierrors "wiki/errors"
+
+	"github.com/google/uuid"
+)
+
+// resolvePageId looks up a page by slug, old slug, alias or uuid.
+func resolvePageId(ctx context.Context, db *sql.DB, id string) (uuid.UUID, error) {
+	pageId, err := database.GetUUID(ctx, db, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, wikierrors.PageNotFound()
+	}
+	if err != nil {
+		return uuid.Nil, wikierrors.DatabaseError(err)
+	}
+	return pageId, nil
+}
+
+// GetRedirects lists the old slugs and aliases that redirect to a page.
+func GetRedirects(ctx context.Context, db *sql.DB, id string) ([]database.RedirectInfo, error) {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return nil, err
+	}
+	redirects, err := database.GetPageRedirects(ctx, db, pageId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return redirects, nil
+}
+
+// CreateAlias makes slug redirect to a page. The slug can't be in use by a
+// page or another redirect.
+func CreateAlias(ctx context.Context, db *sql.DB, id string, slug string, user string) error {
+	if !database.IsValidSlug(slug) {
+		return wikierrors.InvalidSlug()
+	}
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	created, err := database.CreateAlias(ctx, db, pageId, slug, user)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if !created {
+		return wikierrors.SlugTaken(fmt.Errorf("slug %q is taken", slug))
+	}
+	return nil
+}
+
+// DeleteRedirect stops slug redirecting to a page, whether it's an alias or
+// an old slug.
+func DeleteRedirect(ctx context.Context, db *sql.DB, id string, slug string) error {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	deleted, err := database.DeleteRedirect(ctx, db, pageId, slug)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if !deleted {
+		return wikierrors.RedirectNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/utils"
+)
+
+// Maintenance reports, by the name used in /reports/:report
+const (
+	ReportWantedPages        = "wanted-pages"
+	ReportDeletedLinks       = "deleted-links"
+	ReportOrphanPages        = "orphan-pages"
+	ReportUncategorizedPages = "uncategorized-pages"
+)
+
+// GetReport runs the maintenance report with the given name. Links from
+// deleted pages are ignored by all of them.
+func GetReport(ctx context.Context, db *sql.DB, report string, ind int, count int) (any, error) {
+	switch report {
+	case ReportWantedPages:
+		return GetWantedPages(ctx, db, ind, count)
+	case ReportDeletedLinks:
+		return GetDeletedLinks(ctx, db, ind, count)
+	case ReportOrphanPages:
+		return GetOrphanPages(ctx, db, ind, count)
+	case ReportUncategorizedPages:
+		return GetUncategorizedPages(ctx, db, ind, count)
+	default:
+		return nil, wikierrors.ReportNotFound()
+	}
+}
+
+// GetWantedPages lists the slugs pages link to that no page or redirect
+// has, the most linked to first.
+func GetWantedPages(ctx context.Context, db *sql.DB, ind int, count int) ([]utils.WantedPage, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT l.target_slug, COUNT(DISTINCT l.source_id) AS links
+		FROM page_links l
+		JOIN pages s ON s.uuid = l.source_id
+		WHERE s.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM pages p WHERE p.slug = l.target_slug)
+		AND NOT EXISTS (SELECT 1 FROM slug_redirects r WHERE r.slug = l.target_slug)
+		GROUP BY l.target_slug
+		ORDER BY links DESC, l.target_slug
+		LIMIT $1
+		OFFSET $2;
+	`, count, ind)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	defer rows.Close()
+
+	wanted := []utils.WantedPage{}
+	for rows.Next() {
+		var w utils.WantedPage
+		err = rows.Scan(&w.Slug, &w.Links)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		wanted = append(wanted, w)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return wanted, nil
+}
+
+// GetDeletedLinks lists links to deleted pages, by their slug or a slug
+// that redirects to them, the most recently deleted first.
+func GetDeletedLinks(ctx context.Context, db *sql.DB, ind int, count int) ([]utils.DeletedLink, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT s.uuid, s.slug, s.name, l.target_slug, t.uuid, t.slug, t.name, t.deleted_at
+		FROM page_links l
+		JOIN pages s ON s.uuid = l.source_id
+		JOIN pages t ON t.uuid = COALESCE(
+			(SELECT p.uuid FROM pages p WHERE p.slug = l.target_slug),
+			(SELECT r.page_id FROM slug_redirects r WHERE r.slug = l.target_slug))
+		WHERE s.deleted_at IS NULL
+		AND t.deleted_at IS NOT NULL
+		ORDER BY t.deleted_at DESC, s.name
+		LIMIT $1
+		OFFSET $2;
+	`, count, ind)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	defer rows.Close()
+
+	links := []utils.DeletedLink{}
+	for rows.Next() {
+		var l utils.DeletedLink
+		err = rows.Scan(&l.Page.UUID, &l.Page.Slug, &l.Page.Name, &l.TargetSlug,
+			&l.Target.UUID, &l.Target.Slug, &l.Target.Name, &l.DeletedAt)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		links = append(links, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return links, nil
+}
+
+// GetOrphanPages lists pages no other page links to.
+func GetOrphanPages(ctx context.Context, db *sql.DB, ind int, count int) ([]database.PageLink, error) {
+	return queryReportPages(ctx, db, `
+		SELECT p.uuid, p.slug, p.name
+		FROM pages p
+		WHERE p.deleted_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM page_links l
+			JOIN pages s ON s.uuid = l.source_id
+			WHERE s.deleted_at IS NULL
+			AND s.uuid <> p.uuid
+			AND (l.target_slug = p.slug
+				OR l.target_slug IN (SELECT r.slug FROM slug_redirects r WHERE r.page_id = p.uuid))
+		)
+		ORDER BY p.name
+		LIMIT $1
+		OFFSET $2;
+	`, ind, count)
+}
+
+// GetUncategorizedPages lists pages that aren't in any category.
+func GetUncategorizedPages(ctx context.Context, db *sql.DB, ind int, count int) ([]database.PageLink, error) {
+	return queryReportPages(ctx, db, `
+		SELECT p.uuid, p.slug, p.name
+		FROM pages p
+		WHERE p.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM page_categories pc WHERE pc.page_id = p.uuid)
+		ORDER BY p.name
+		LIMIT $1
+		OFFSET $2;
+	`, ind, count)
+}
+
+func queryReportPages(ctx context.Context, db *sql.DB, query string, ind int, count int) ([]database.PageLink, error) {
+	rows, err := db.QueryContext(ctx, query, count, ind)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	defer rows.Close()
+
+	pages := []database.PageLink{}
+	for rows.Next() {
+		var p database.PageLink
+		err = rows.Scan(&p.UUID, &p.Slug, &p.Name)
+		if err != nil {
+			return nil, wikierrors.DatabaseError(err)
+		}
+		pages = append(pages, p)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return pages, nil
+}
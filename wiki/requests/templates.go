@@ -0,0 +1,38 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"wiki/database"
+	wikierrors "wiki/errors"
+)
+
+// GetReindexQueue lists the pages to index again because a template they
+// include changed.
+func GetReindexQueue(ctx context.Context, db *sql.DB, count int) ([]database.ReindexEntry, error) {
+	entries, err := database.GetReindexQueue(ctx, db, count)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return entries, nil
+}
+
+// ClearReindexMark takes a page off the reindex queue once it's been
+// indexed. markedAt is the time the queue gave for it, so a page marked
+// again in the meantime stays queued.
+func ClearReindexMark(ctx context.Context, db *sql.DB, id string, markedAt string) error {
+	pageId, err := resolvePageId(ctx, db, id)
+	if err != nil {
+		return err
+	}
+	marked, err := time.Parse(time.RFC3339Nano, markedAt)
+	if err != nil {
+		return wikierrors.InvalidMarkedAt(err)
+	}
+	err = database.ClearReindexMark(ctx, db, pageId, marked)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	return nil
+}
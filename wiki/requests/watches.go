@@ -0,0 +1,155 @@
+package requests
+
+import (
+	"context"
+	"database/sql"
+	"wiki/database"
+	wikierrors "wiki/errors"
+	"wiki/utils"
+
+	"github.com/google/uuid"
+)
+
+// parseUser reads the auth user ID that watches and notifications belong to.
+func parseUser(userId string) (uuid.UUID, error) {
+	if userId == "" {
+		return uuid.Nil, wikierrors.UserRequired()
+	}
+	id, err := uuid.Parse(userId)
+	if err != nil {
+		return uuid.Nil, wikierrors.InvalidID(err)
+	}
+	return id, nil
+}
+
+// GetWatches lists what a user watches.
+func GetWatches(ctx context.Context, db *sql.DB, userId string) ([]database.Watch, error) {
+	user, err := parseUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	watches, err := database.GetWatches(ctx, db, user)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	return watches, nil
+}
+
+// AddWatch makes a user watch a page (by slug or uuid) or a category and its
+// subcategories (by slug path). Exactly one of them must be given.
+func AddWatch(ctx context.Context, db *sql.DB, userId string, email string, page string, category string) (*database.Watch, error) {
+	user, err := parseUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, wikierrors.UserRequired()
+	}
+	if (page == "") == (category == "") {
+		return nil, wikierrors.InvalidWatch("give either a page or a category to watch")
+	}
+
+	var pageId *uuid.UUID
+	var categoryId *int
+	if page != "" {
+		id, err := resolvePageId(ctx, db, page)
+		if err != nil {
+			return nil, err
+		}
+		pageId = &id
+	} else {
+		cat, err := database.GetCategoryBySlugPath(ctx, db, category)
+		if err != nil {
+			return nil, err
+		}
+		categoryId = &cat.ID
+	}
+
+	id, err := database.AddWatch(ctx, db, user, email, pageId, categoryId)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	watches, err := database.GetWatches(ctx, db, user)
+	if err != nil {
+		return nil, wikierrors.DatabaseError(err)
+	}
+	for _, w := range watches {
+		if w.UUID == id {
+			return &w, nil
+		}
+	}
+	return nil, wikierrors.WatchNotFound()
+}
+
+// DeleteWatch stops a user watching something.
+func DeleteWatch(ctx context.Context, db *sql.DB, userId string, id string) error {
+	user, err := parseUser(userId)
+	if err != nil {
+		return err
+	}
+	watchId, err := uuid.Parse(id)
+	if err != nil {
+		return wikierrors.InvalidID(err)
+	}
+	deleted, err := database.DeleteWatch(ctx, db, user, watchId)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	if !deleted {
+		return wikierrors.WatchNotFound()
+	}
+	return nil
+}
+
+// GetInbox returns a page of a user's notifications, newest first, with
+// their unread count.
+func GetInbox(ctx context.Context, db *sql.DB, userId string, unreadOnly bool, index int, count int) (utils.Inbox, error) {
+	user, err := parseUser(userId)
+	if err != nil {
+		return utils.Inbox{}, err
+	}
+	notifications, err := database.GetNotifications(ctx, db, user, unreadOnly, index, count)
+	if err != nil {
+		return utils.Inbox{}, wikierrors.DatabaseError(err)
+	}
+	unread, err := database.CountUnreadNotifications(ctx, db, user)
+	if err != nil {
+		return utils.Inbox{}, wikierrors.DatabaseError(err)
+	}
+	return utils.Inbox{Notifications: notifications, Unread: unread}, nil
+}
+
+// CountUnread counts the notifications a user hasn't read.
+func CountUnread(ctx context.Context, db *sql.DB, userId string) (int, error) {
+	user, err := parseUser(userId)
+	if err != nil {
+		return 0, err
+	}
+	unread, err := database.CountUnreadNotifications(ctx, db, user)
+	if err != nil {
+		return 0, wikierrors.DatabaseError(err)
+	}
+	return unread, nil
+}
+
+// MarkRead marks some of a user's notifications read, or all of them if no
+// ids are given.
+func MarkRead(ctx context.Context, db *sql.DB, userId string, ids []string) error {
+	user, err := parseUser(userId)
+	if err != nil {
+		return err
+	}
+	notificationIds := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		notificationId, err := uuid.Parse(id)
+		if err != nil {
+			return wikierrors.InvalidID(err)
+		}
+		notificationIds = append(notificationIds, notificationId)
+	}
+	err = database.MarkNotificationsRead(ctx, db, user, notificationIds)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/bluekeyes/go-gitdiff/gitdiff"
+	"github.com/google/uuid"
+)
+
+// BlameLine is a line of page content with the revision that last changed it.
+// Line numbers are 1-based.
+type BlameLine struct {
+	Line		int			`json:"line"`
+	Content		string		`json:"content"`
+	Revision	uuid.UUID	`json:"revision"`
+	Author		string		`json:"author"`
+	DateTime	time.Time	`json:"date_time"`
+}
+
+type PageBlame struct {
+	PageId		uuid.UUID	`json:"page_id"`
+	Revision	uuid.UUID	`json:"revision"`
+	Lines		[]BlameLine	`json:"lines"`
+}
+
+// BlameSource is a line as blame replays history: its content (newline
+// included) and the index of the revision that introduced it.
+type BlameSource struct {
+	Content	string
+	Rev		int
+}
+
+// ApplyBlameDiff applies the revision diff at index rev to the lines of the
+// revision before it. Lines the diff adds are attributed to rev; context lines
+// keep their attribution.
+func ApplyBlameDiff(lines []BlameSource, revDiff string, rev int) ([]BlameSource, error) {
+	files, _, err := gitdiff.Parse(bytes.NewReader([]byte(revDiff)))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse revision: %w", err)
+	}
+	if len(files) == 0 {
+		return lines, nil
+	}
+
+	result := make([]BlameSource, 0, len(lines))
+	next := 0
+	for _, frag := range files[0].TextFragments {
+		start := int(frag.OldPosition)
+		if frag.OldLines > 0 {
+			start--
+		}
+		if start < next || start > len(lines) {
+			return nil, fmt.Errorf("revision fragment at line %d is out of range", frag.OldPosition)
+		}
+		result = append(result, lines[next:start]...)
+		next = start
+
+		for _, l := range frag.Lines {
+			if l.Op == gitdiff.OpAdd {
+				result = append(result, BlameSource{Content: l.Line, Rev: rev})
+				continue
+			}
+			if next >= len(lines) || lines[next].Content != l.Line {
+				return nil, fmt.Errorf("conflict while applying revision at line %d", next+1)
+			}
+			if l.Op == gitdiff.OpContext {
+				result = append(result, lines[next])
+			}
+			next++
+		}
+	}
+	return append(result, lines[next:]...), nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aymanbagabas/go-udiff"
+)
+
+func TestApplyBlameDiff(t *testing.T) {
+	versions := []string{
+		"",
+		"Dan Boone\nwas born in 1734.\nHe explored Kentucky.\n",
+		"Dan Boone\nwas born in 1735.\nHe explored Kentucky.\n",
+		"Daniel Boone\nwas born in 1735.\nHe explored Kentucky.\nHe died in 1820.",
+	}
+
+	var lines []BlameSource
+	for i := 1; i < len(versions); i++ {
+		diff := udiff.Unified("page.md", "page.md", versions[i-1], versions[i])
+		var err error
+		lines, err = ApplyBlameDiff(lines, diff, i)
+		if err != nil {
+			t.Fatalf("revision %d: %v", i, err)
+		}
+	}
+
+	var content strings.Builder
+	var revs []int
+	for _, l := range lines {
+		content.WriteString(l.Content)
+		revs = append(revs, l.Rev)
+	}
+	if content.String() != versions[len(versions)-1] {
+		t.Errorf("replayed content = %q, want %q", content.String(), versions[len(versions)-1])
+	}
+	want := []int{3, 2, 1, 3}
+	if len(revs) != len(want) {
+		t.Fatalf("got %d lines, want %d", len(revs), len(want))
+	}
+	for i := range want {
+		if revs[i] != want[i] {
+			t.Errorf("line %d attributed to revision %d, want %d", i+1, revs[i], want[i])
+		}
+	}
+}
+
+func TestApplyBlameDiffConflict(t *testing.T) {
+	diff := udiff.Unified("page.md", "page.md", "one\ntwo\n", "one\nthree\n")
+	_, err := ApplyBlameDiff([]BlameSource{{"one\n", 0}, {"other\n", 0}}, diff, 1)
+	if err == nil {
+		t.Error("expected an error applying a diff to the wrong content")
+	}
+}
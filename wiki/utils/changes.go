@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of change in recent changes. Restores and reverts are edits.
+const (
+	ChangeCreate = "create"
+	ChangeEdit   = "edit"
+	ChangeDelete = "delete"
+)
+
+// Namespaces a page can be in, going by its slug.
+const (
+	NamespaceMain     = "main"
+	NamespaceTemplate = "template"
+)
+
+// Change is a revision as listed in recent changes.
+type Change struct {
+	UUID			uuid.UUID	`json:"uuid"`
+	PageId			uuid.UUID	`json:"page_id"`
+	DateTime		time.Time	`json:"date_time"`
+	Author			string		`json:"author"`
+	Slug			string		`json:"slug"`
+	Name			string		`json:"name"`
+	Type			string		`json:"type"`
+	Summary			string		`json:"summary"`
+	Minor			bool		`json:"minor"`
+	RevertedFrom	*uuid.UUID	`json:"reverted_from"`
+	// DiffStats is only filled in when asked for
+	DiffStats		*DiffStats	`json:"diff_stats,omitempty"`
+}
+
+// RecentChanges is one page of recent changes, newest first. NextCursor
+// gets the page after it, and is empty on the last one.
+type RecentChanges struct {
+	Changes		[]Change	`json:"changes"`
+	NextCursor	string		`json:"next_cursor,omitempty"`
+}
+
+// ChangeCursor is where a page of recent changes ended. The next page
+// starts with the changes before it, so new edits don't shift the pages
+// the way an offset would.
+type ChangeCursor struct {
+	DateTime	time.Time
+	UUID		uuid.UUID
+}
+
+// NewChangeCursor returns the cursor for the page after change.
+func NewChangeCursor(change Change) ChangeCursor {
+	return ChangeCursor{change.DateTime, change.UUID}
+}
+
+// String encodes the cursor for a URL.
+func (c ChangeCursor) String() string {
+	raw := strconv.FormatInt(c.DateTime.UnixMicro(), 10) + "." + c.UUID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseChangeCursor decodes a cursor made by ChangeCursor.String.
+func ParseChangeCursor(s string) (ChangeCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	micros, id, found := strings.Cut(string(raw), ".")
+	if !found {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor")
+	}
+	n, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	revId, err := uuid.Parse(id)
+	if err != nil {
+		return ChangeCursor{}, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return ChangeCursor{time.UnixMicro(n).UTC(), revId}, nil
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestChangeCursor(t *testing.T) {
+	cursor := ChangeCursor{time.Date(2026, 10, 18, 14, 2, 11, 123456000, time.UTC), uuid.New()}
+
+	got, err := ParseChangeCursor(cursor.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.DateTime.Equal(cursor.DateTime) || got.UUID != cursor.UUID {
+		t.Errorf("got %v, want %v", got, cursor)
+	}
+
+	for _, bad := range []string{"", "not a cursor", "MTIz"} {
+		if _, err := ParseChangeCursor(bad); err == nil {
+			t.Errorf("ParseChangeCursor(%q) accepted", bad)
+		}
+	}
+}
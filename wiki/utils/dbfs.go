@@ -6,8 +6,6 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"time"
 	"wiki/database"
 	wikierrors "wiki/errors"
@@ -18,49 +16,53 @@ import (
 	"github.com/google/uuid"
 )
 
-func CreateRevision(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, revReq RevisionRequest) (uuid.UUID, error) {
+func CreateRevision(ctx context.Context, db *sql.DB, tx *sql.Tx, store filesystem.Storage, revReq RevisionRequest) (uuid.UUID, error) {
 	pageUUID, err := database.GetUUID(ctx, db, revReq.PageId)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("GetUUID failed: %w", err)
 	}
-	var revUUID uuid.UUID
+	// Get the current last revision ID to reconstruct the content at that revision.
+	// This has to happen before the insert: the revisions trigger moves
+	// last_revision_id to the new row.
+	var lastRevisionId *uuid.UUID
 	err = tx.QueryRowContext(ctx, `
-			INSERT INTO revisions (page_id, author, slug, name, archive_date, deleted_at)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING uuid;
-			`, pageUUID, revReq.Author, revReq.Slug, revReq.Name, revReq.ArchiveDate, revReq.DeletedAt).Scan(&revUUID)
+		SELECT last_revision_id FROM pages WHERE uuid=$1;
+	`, pageUUID).Scan(&lastRevisionId)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
 
-	// Get the current last revision ID to reconstruct the content at that revision
-	var lastRevisionId *uuid.UUID
+	var revUUID uuid.UUID
 	err = tx.QueryRowContext(ctx, `
-		SELECT last_revision_id FROM pages WHERE uuid=$1;
-	`, pageUUID).Scan(&lastRevisionId)
+			INSERT INTO revisions (page_id, author, slug, name, archive_date, deleted_at, reverted_from, summary, minor, date_time)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE($10, now()))
+			RETURNING uuid;
+			`, pageUUID, revReq.Author, revReq.Slug, revReq.Name, revReq.ArchiveDate, revReq.DeletedAt, revReq.RevertedFrom,
+		revReq.Summary, revReq.Minor, revReq.DateTime).Scan(&revUUID)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
 
+	// only reaches watchers if the revision's transaction commits
+	err = database.NotifyWatchers(ctx, tx, pageUUID, &revUUID, database.NotifyEdit, revReq.Author, revReq.Summary, nil)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("notifying watchers failed: %w", err)
+	}
+
 	// Get the content at the last revision (or empty string if this is the first revision)
 	var pageContent string
 	if lastRevisionId != nil {
-		pageContent, err = GetContentAtRevision(ctx, db, dataDir, pageUUID, *lastRevisionId)
+		pageContent, err = GetContentAtRevision(ctx, db, store, pageUUID, *lastRevisionId)
 		if err != nil {
 			return uuid.UUID{}, err
 		}
 	}
 
 	// create the diff and make the revision
-	pageFilename := filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", revReq.Slug))
+	pageFilename := filesystem.GetPageFilename(pageUUID)
 	diff := udiff.Unified(pageFilename, pageFilename, pageContent, revReq.NewContent)
 
-	filename := fmt.Sprintf("%s_%s.txt", revReq.Slug, revUUID)
-	err = os.MkdirAll(filepath.Join(dataDir, "revisions"), 0755)
-	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("creating revisions directory: %w", err)
-	}
-	err = os.WriteFile(filepath.Join(dataDir, "revisions", filename), []byte(diff), 0644)
+	err = store.PutRevision(ctx, pageUUID, revUUID, diff)
 	if err != nil {
 		return uuid.UUID{}, fmt.Errorf("writing revision file failed: %w", err)
 	}
@@ -68,7 +70,7 @@ func CreateRevision(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string,
 	return revUUID, nil
 }
 
-func UpdatePage(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, revId uuid.UUID) error {
+func UpdatePage(ctx context.Context, db *sql.DB, tx *sql.Tx, store filesystem.Storage, revId uuid.UUID) error {
 	var revInfo database.RevInfo
 	err := tx.QueryRowContext(ctx, `
 		SELECT uuid, page_id, date_time, author, slug, name, archive_date, deleted_at
@@ -79,14 +81,7 @@ func UpdatePage(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, rev
 	if err != nil {
 		return err
 	}
-	var currSlug string
-	err = tx.QueryRowContext(ctx, `
-		SELECT slug FROM pages WHERE uuid=$1;
-	`, revInfo.PageId).Scan(&currSlug)
-	if err != nil {
-		return err
-	}
-	contentAtRev, err := GetContentAtRevision(ctx, db, dataDir, *revInfo.PageId, revId)
+	contentAtRev, err := GetContentAtRevision(ctx, db, store, *revInfo.PageId, revId)
 	if err != nil {
 		return wikierrors.DatabaseFilesystemError(err)
 	}
@@ -100,60 +95,22 @@ func UpdatePage(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, rev
 		return wikierrors.DatabaseError(err)
 	}
 
-	if revInfo.Slug != currSlug {
-		err = os.Rename(filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", currSlug)),
-			filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", revInfo.Slug)))
-		if err != nil {
-			return err
-		}
-		revs, err := tx.QueryContext(ctx, `
-			SELECT uuid FROM revisions WHERE page_id=$1;
-		`, revInfo.PageId)
-		if err != nil {
-			return err
-		}
-		defer revs.Close()
-		for revs.Next() {
-			var currRevId uuid.UUID
-			err = revs.Scan(&currRevId)
-			if err != nil {
-				return err
-			}
-			if currRevId == *revInfo.UUID {
-				continue
-			}
-			err = os.Rename(filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", currSlug, currRevId)),
-				filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", revInfo.Slug, currRevId)))
-			if err != nil {
-				return err
-			}
-		}
-		revs.Close()
-		snaps, err := tx.QueryContext(ctx, `
-			SELECT uuid FROM snapshots WHERE page=$1;
-		`, revInfo.PageId)
-		if err != nil {
-			return err
-		}
-		defer snaps.Close()
-		for snaps.Next() {
-			var currSnapId uuid.UUID
-			err = snaps.Scan(&currSnapId)
-			if err != nil {
-				return err
-			}
-			err = os.Rename(filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", currSlug, currSnapId)),
-				filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", revInfo.Slug, currSnapId)))
-			if err != nil {
-				return err
-			}
-		}
-		snaps.Close()
+	err = database.SetPageLinks(ctx, tx, *revInfo.PageId, ParseWikiLinks(contentAtRev))
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	err = database.SetPageTemplates(ctx, tx, *revInfo.PageId, ParseTemplateIncludes(contentAtRev))
+	if err != nil {
+		return wikierrors.DatabaseError(err)
+	}
+	// pages showing this one as a template need indexing again. Only
+	// template slugs are ever included, so for other pages this is a no-op.
+	err = database.MarkTemplateIncluders(ctx, tx, *revInfo.PageId)
+	if err != nil {
+		return wikierrors.DatabaseError(err)
 	}
 
-	pageFilename := fmt.Sprintf("%s.md", revInfo.Slug)
-	pageFilepath := filepath.Join(dataDir, "pages", pageFilename)
-	err = os.WriteFile(pageFilepath, []byte(contentAtRev), 0644)
+	err = store.PutPage(ctx, *revInfo.PageId, contentAtRev)
 	if err != nil {
 		return wikierrors.FilesystemError(err)
 	}
@@ -161,7 +118,7 @@ func UpdatePage(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, rev
 	return nil
 }
 
-func CreateSnapshot(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string, pageId uuid.UUID, revId uuid.UUID) (uuid.UUID, error) {
+func CreateSnapshot(ctx context.Context, db *sql.DB, tx *sql.Tx, store filesystem.Storage, pageId uuid.UUID, revId uuid.UUID) (uuid.UUID, error) {
 	var snapUUID uuid.UUID
 	err := tx.QueryRowContext(ctx, `
 			INSERT INTO snapshots (page, revision)
@@ -172,25 +129,12 @@ func CreateSnapshot(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string,
 		return uuid.UUID{}, err
 	}
 
-	snapContent, err := GetContentAtRevision(ctx, db, dataDir, pageId, revId)
+	snapContent, err := GetContentAtRevision(ctx, db, store, pageId, revId)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
 
-	var pageSlug string
-	err = tx.QueryRowContext(ctx, `
-		SELECT slug FROM pages WHERE uuid=$1;
-	`, pageId).Scan(&pageSlug)
-	if err != nil {
-		return uuid.UUID{}, err
-	}
-
-	filename := fmt.Sprintf("%s_%s.md", pageSlug, snapUUID)
-	err = os.MkdirAll(filepath.Join(dataDir, "snapshots"), 0755)
-	if err != nil {
-		return uuid.UUID{}, fmt.Errorf("creating snapshots directory: %w", err)
-	}
-	err = os.WriteFile(filepath.Join(dataDir, "snapshots", filename), []byte(snapContent), 0644)
+	err = store.PutSnapshot(ctx, pageId, snapUUID, snapContent)
 	if err != nil {
 		return uuid.UUID{}, err
 	}
@@ -198,7 +142,10 @@ func CreateSnapshot(ctx context.Context, db *sql.DB, tx *sql.Tx, dataDir string,
 	return snapUUID, nil
 }
 
-func GetContentAtRevision(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID, revId uuid.UUID) (string, error) {
+func GetContentAtRevision(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId uuid.UUID, revId uuid.UUID) (string, error) {
+	if content, ok := revisionCache.Get(revId); ok {
+		return content, nil
+	}
 	lastSnap, err := database.GetMostRecentSnapshot(ctx, db, revId)
 	if err == sql.ErrNoRows {
 		return "", wikierrors.RevisionNotFound()
@@ -210,7 +157,7 @@ func GetContentAtRevision(ctx context.Context, db *sql.DB, dataDir string, pageI
 	if err != nil {
 		return "", wikierrors.DatabaseError(err)
 	}
-	revContent, err := filesystem.GetSnapshotContent(ctx, db, dataDir, lastSnap.UUID)
+	revContent, err := filesystem.GetSnapshotContent(ctx, store, pageId, lastSnap.UUID)
 	if err != nil {
 		return "", wikierrors.FilesystemError(err)
 	}
@@ -218,38 +165,48 @@ func GetContentAtRevision(ctx context.Context, db *sql.DB, dataDir string, pageI
 	// i hope and pray that this works
 	// update: it worked. most errors were elsewhere :)
 	for _, r := range missingRevs {
-		revDiff, err := filesystem.GetRevisionContent(ctx, db, dataDir, *r.UUID)
+		revDiff, err := filesystem.GetRevisionContent(ctx, store, pageId, *r.UUID)
 		if err != nil {
 			return "", wikierrors.FilesystemError(err)
 		}
-		files, _, err := gitdiff.Parse(bytes.NewReader([]byte(revDiff)))
+		revContent, err = ApplyRevisionDiff(revContent, revDiff)
 		if err != nil {
-			return "", fmt.Errorf("couldn't parse revision: %w", err)
-		}
-		if len(files) == 0 {
-			continue
+			return "", err
 		}
-		src := bytes.NewReader([]byte(revContent))
-		var dst bytes.Buffer
+	}
+	revisionCache.Put(revId, revContent)
+	return revContent, nil
+}
 
-		err = gitdiff.Apply(&dst, src, files[0])
-		if err != nil {
-			if errors.Is(err, &gitdiff.Conflict{}) {
-				return "", fmt.Errorf("conflict while applying revision: %w", err)
-			}
-			return "", fmt.Errorf("applying revision: %w", err)
+// ApplyRevisionDiff applies a revision's unified diff to the content of the
+// revision before it.
+func ApplyRevisionDiff(content string, revDiff string) (string, error) {
+	files, _, err := gitdiff.Parse(bytes.NewReader([]byte(revDiff)))
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse revision: %w", err)
+	}
+	if len(files) == 0 {
+		return content, nil
+	}
+	src := bytes.NewReader([]byte(content))
+	var dst bytes.Buffer
+
+	err = gitdiff.Apply(&dst, src, files[0])
+	if err != nil {
+		if errors.Is(err, &gitdiff.Conflict{}) {
+			return "", fmt.Errorf("conflict while applying revision: %w", err)
 		}
-		revContent = dst.String()
+		return "", fmt.Errorf("applying revision: %w", err)
 	}
-	return revContent, nil
+	return dst.String(), nil
 }
 
-func GetPageInfoPreview(ctx context.Context, db *sql.DB, dataDir string, pageId uuid.UUID) (*PageInfoPrev, error) {
+func GetPageInfoPreview(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId uuid.UUID) (*PageInfoPrev, error) {
 	pageInfo, err := database.GetPageInfo(ctx, db, pageId)
 	if err != nil {
 		return nil, err
 	}
-	preview, err := filesystem.GetPagePreview(ctx, db, dataDir, pageId, 250)
+	preview, err := filesystem.GetPagePreview(ctx, store, pageId, 250)
 	if err != nil {
 		return nil, err
 	}
@@ -278,7 +235,7 @@ func GetPageInfoPreview(ctx context.Context, db *sql.DB, dataDir string, pageId
 	}, nil
 }
 
-func GetIndexInfo(ctx context.Context, db *sql.DB, dataDir string, pageId string) (*IndexInfo, error) {
+func GetIndexInfo(ctx context.Context, db *sql.DB, store filesystem.Storage, pageId string) (*IndexInfo, error) {
 	pageUUID, err := database.GetUUID(ctx, db, pageId)
 	if err != nil {
 		return nil, err
@@ -308,9 +265,37 @@ func GetIndexInfo(ctx context.Context, db *sql.DB, dataDir string, pageId string
 	} else {
 		indexInfo.ArchiveDate = time.Time{}
 	}
-	indexInfo.Content, err = filesystem.GetPageContent(ctx, db, dataDir, pageUUID)
+	content, err := filesystem.GetPageContent(ctx, store, pageUUID)
+	if err != nil {
+		return nil, err
+	}
+	// search sees the page as readers do, with its templates filled in
+	indexInfo.Content, err = ExpandTemplates(content, PageTemplates(ctx, db, store))
 	if err != nil {
 		return nil, err
 	}
 	return &indexInfo, nil
 }
+
+// PageTemplates looks templates up among the wiki's pages, following
+// redirects. Deleted pages count as missing.
+func PageTemplates(ctx context.Context, db *sql.DB, store filesystem.Storage) TemplateLookup {
+	return func(slug string) (string, bool, error) {
+		pageId, err := database.GetUUID(ctx, db, slug)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+		deleted, err := database.GetPageDeleted(ctx, db, pageId)
+		if err != nil || deleted {
+			return "", false, err
+		}
+		content, err := filesystem.GetPageContent(ctx, store, pageId)
+		if err != nil {
+			return "", false, err
+		}
+		return content, true, nil
+	}
+}
@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/google/uuid"
+)
+
+// Kinds of diff lines and word segments.
+const (
+	DiffEqual  = "equal"
+	DiffDelete = "delete"
+	DiffInsert = "insert"
+)
+
+// maxWordDiffCells caps the size of the word table compared for one pair of
+// lines; longer lines are shown as a whole-line change instead.
+const maxWordDiffCells = 250000
+
+type RevisionDiff struct {
+	PageId	uuid.UUID	`json:"page_id"`
+	From	*uuid.UUID	`json:"from"`
+	To		uuid.UUID	`json:"to"`
+	Hunks	[]DiffHunk	`json:"hunks"`
+}
+
+// DiffHunk is a run of changed lines with context around them. Line numbers
+// are 1-based.
+type DiffHunk struct {
+	FromLine	int			`json:"from_line"`
+	FromCount	int			`json:"from_count"`
+	ToLine		int			`json:"to_line"`
+	ToCount		int			`json:"to_count"`
+	Lines		[]DiffLine	`json:"lines"`
+}
+
+// DiffLine is one line of a hunk. A deleted line and the inserted line that
+// replaced it also carry a word-level diff between the two.
+type DiffLine struct {
+	Kind	string			`json:"kind"`
+	Content	string			`json:"content"`
+	Words	[]DiffSegment	`json:"words,omitempty"`
+}
+
+type DiffSegment struct {
+	Kind	string	`json:"kind"`
+	Text	string	`json:"text"`
+}
+
+// DiffHunks compares two versions of a page line by line, then word by word
+// within changed lines.
+func DiffHunks(from string, to string, contextLines int) ([]DiffHunk, error) {
+	unified, err := udiff.ToUnifiedDiff("from", "to", from, udiff.Strings(from, to), contextLines)
+	if err != nil {
+		return nil, err
+	}
+
+	hunks := make([]DiffHunk, 0, len(unified.Hunks))
+	for _, h := range unified.Hunks {
+		hunk := DiffHunk{FromLine: h.FromLine, ToLine: h.ToLine, Lines: make([]DiffLine, len(h.Lines))}
+		for i, l := range h.Lines {
+			hunk.Lines[i] = DiffLine{Kind: diffKind(l.Kind), Content: strings.TrimSuffix(l.Content, "\n")}
+			if l.Kind != udiff.Insert {
+				hunk.FromCount++
+			}
+			if l.Kind != udiff.Delete {
+				hunk.ToCount++
+			}
+		}
+		pairChangedLines(hunk.Lines)
+		hunks = append(hunks, hunk)
+	}
+	return hunks, nil
+}
+
+// UnifiedRevisionDiff renders the same comparison as DiffHunks as unified
+// diff text.
+func UnifiedRevisionDiff(fromLabel string, toLabel string, from string, to string, contextLines int) (string, error) {
+	return udiff.ToUnified(fromLabel, toLabel, from, udiff.Strings(from, to), contextLines)
+}
+
+func diffKind(kind udiff.OpKind) string {
+	switch kind {
+	case udiff.Delete:
+		return DiffDelete
+	case udiff.Insert:
+		return DiffInsert
+	default:
+		return DiffEqual
+	}
+}
+
+// pairChangedLines word-diffs each block of deleted lines against the
+// inserted lines that follow it, pairing them up in order.
+func pairChangedLines(lines []DiffLine) {
+	for i := 0; i < len(lines); {
+		if lines[i].Kind != DiffDelete {
+			i++
+			continue
+		}
+		delStart := i
+		for i < len(lines) && lines[i].Kind == DiffDelete {
+			i++
+		}
+		insStart := i
+		for i < len(lines) && lines[i].Kind == DiffInsert {
+			i++
+		}
+		pairs := min(insStart-delStart, i-insStart)
+		for p := range pairs {
+			del, ins := &lines[delStart+p], &lines[insStart+p]
+			del.Words, ins.Words = DiffWords(del.Content, ins.Content)
+		}
+	}
+}
+
+// DiffWords compares two lines word by word, returning the segments of the
+// old line (equal and deleted) and of the new line (equal and inserted).
+// Whitespace runs and punctuation count as words of their own.
+func DiffWords(from string, to string) (fromWords []DiffSegment, toWords []DiffSegment) {
+	a, b := splitWords(from), splitWords(to)
+	if len(a)*len(b) > maxWordDiffCells {
+		return []DiffSegment{{DiffDelete, from}}, []DiffSegment{{DiffInsert, to}}
+	}
+
+	// longest common subsequence, filled in from the end
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			fromWords = appendSegment(fromWords, DiffEqual, a[i])
+			toWords = appendSegment(toWords, DiffEqual, b[j])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			fromWords = appendSegment(fromWords, DiffDelete, a[i])
+			i++
+		default:
+			toWords = appendSegment(toWords, DiffInsert, b[j])
+			j++
+		}
+	}
+	return fromWords, toWords
+}
+
+func appendSegment(segments []DiffSegment, kind string, text string) []DiffSegment {
+	if n := len(segments); n > 0 && segments[n-1].Kind == kind {
+		segments[n-1].Text += text
+		return segments
+	}
+	return append(segments, DiffSegment{kind, text})
+}
+
+func splitWords(s string) []string {
+	var words []string
+	start := 0
+	class := func(r rune) int {
+		switch {
+		case unicode.IsSpace(r):
+			return 0
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			return 1
+		default:
+			return 2
+		}
+	}
+	prev := -1
+	for i, r := range s {
+		c := class(r)
+		// punctuation is always split, one character at a time
+		if i > start && (c != prev || c == 2) {
+			words = append(words, s[start:i])
+			start = i
+		}
+		prev = c
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// DiffStats counts the lines a revision added and removed.
+type DiffStats struct {
+	Added	int	`json:"added"`
+	Removed	int	`json:"removed"`
+}
+
+// CountDiffLines counts the changed lines of a unified diff, as stored for
+// each revision. The file header before the first hunk isn't counted.
+func CountDiffLines(unified string) DiffStats {
+	var stats DiffStats
+	inHunk := false
+	for line := range strings.SplitSeq(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			stats.Added++
+		case strings.HasPrefix(line, "-"):
+			stats.Removed++
+		}
+	}
+	return stats
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffHunks(t *testing.T) {
+	from := "# Title\n\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nThe quick brown fox\n"
+	to := "# Title\n\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nThe slow brown fox\nten\n"
+
+	hunks, err := DiffHunks(from, to, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 1 {
+		t.Fatalf("got %d hunks, want 1", len(hunks))
+	}
+	h := hunks[0]
+	if h.FromLine != 11 || h.FromCount != 2 || h.ToLine != 11 || h.ToCount != 3 {
+		t.Errorf("got hunk @@ -%d,%d +%d,%d @@, want @@ -11,2 +11,3 @@", h.FromLine, h.FromCount, h.ToLine, h.ToCount)
+	}
+
+	var kinds []string
+	for _, l := range h.Lines {
+		kinds = append(kinds, l.Kind)
+	}
+	wantKinds := []string{DiffEqual, DiffDelete, DiffInsert, DiffInsert}
+	if !reflect.DeepEqual(kinds, wantKinds) {
+		t.Fatalf("got line kinds %v, want %v", kinds, wantKinds)
+	}
+
+	wantDel := []DiffSegment{{DiffEqual, "The "}, {DiffDelete, "quick"}, {DiffEqual, " brown fox"}}
+	wantIns := []DiffSegment{{DiffEqual, "The "}, {DiffInsert, "slow"}, {DiffEqual, " brown fox"}}
+	if !reflect.DeepEqual(h.Lines[1].Words, wantDel) {
+		t.Errorf("deleted line words = %v, want %v", h.Lines[1].Words, wantDel)
+	}
+	if !reflect.DeepEqual(h.Lines[2].Words, wantIns) {
+		t.Errorf("inserted line words = %v, want %v", h.Lines[2].Words, wantIns)
+	}
+	if h.Lines[3].Words != nil {
+		t.Errorf("unpaired inserted line should have no word diff, got %v", h.Lines[3].Words)
+	}
+}
+
+func TestDiffHunksIdentical(t *testing.T) {
+	hunks, err := DiffHunks("same\n", "same\n", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hunks) != 0 {
+		t.Errorf("got %d hunks for identical content, want 0", len(hunks))
+	}
+}
+
+func TestUnifiedRevisionDiffRoundTrips(t *testing.T) {
+	from := "alpha\nbeta\ngamma\n"
+	to := "alpha\nbeta!\ngamma\ndelta\n"
+	diff, err := UnifiedRevisionDiff("a", "b", from, to, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ApplyRevisionDiff(from, diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != to {
+		t.Errorf("applying the unified diff gave %q, want %q", got, to)
+	}
+}
+
+func TestCountDiffLines(t *testing.T) {
+	diff, err := UnifiedRevisionDiff("page.md", "page.md", "one\ntwo\n-- three\n", "one\n--- three\nfour\nfive\n", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := CountDiffLines(diff)
+	if want := (DiffStats{Added: 3, Removed: 2}); got != want {
+		t.Errorf("CountDiffLines = %+v, want %+v\n%s", got, want, diff)
+	}
+	if got := CountDiffLines(""); got != (DiffStats{}) {
+		t.Errorf("CountDiffLines of an empty diff = %+v, want none", got)
+	}
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// FastImportFile is a file a commit writes, with its full content.
+type FastImportFile struct {
+	Path	string
+	Content	string
+}
+
+// FastImportCommit is one commit of a git fast-import stream. Author is an
+// email address; the name git shows is the part before the @.
+type FastImportCommit struct {
+	Author	string
+	When	time.Time
+	Message	string
+	Deletes	[]string
+	Files	[]FastImportFile
+}
+
+// FastImportWriter writes commits on a single branch as a git fast-import
+// stream. The stream declares the "done" feature, so git fast-import rejects
+// a stream that was cut off before Close.
+type FastImportWriter struct {
+	w		*bufio.Writer
+	ref		string
+	mark	int
+	started	bool
+}
+
+func NewFastImportWriter(w io.Writer, ref string) *FastImportWriter {
+	return &FastImportWriter{w: bufio.NewWriter(w), ref: ref}
+}
+
+// Commit writes a commit and flushes it to the underlying writer, so long
+// exports stream out as they go.
+func (f *FastImportWriter) Commit(c FastImportCommit) error {
+	if !f.started {
+		fmt.Fprintf(f.w, "feature done\nreset %s\n\n", f.ref)
+		f.started = true
+	}
+	f.mark++
+	ident := fastImportIdent(c.Author, c.When)
+	fmt.Fprintf(f.w, "commit %s\nmark :%d\nauthor %s\ncommitter %s\n", f.ref, f.mark, ident, ident)
+	writeFastImportData(f.w, c.Message)
+	if f.mark > 1 {
+		fmt.Fprintf(f.w, "from :%d\n", f.mark-1)
+	}
+	for _, path := range c.Deletes {
+		fmt.Fprintf(f.w, "D %s\n", path)
+	}
+	for _, file := range c.Files {
+		fmt.Fprintf(f.w, "M 100644 inline %s\n", file.Path)
+		writeFastImportData(f.w, file.Content)
+	}
+	f.w.WriteString("\n")
+	return f.w.Flush()
+}
+
+// Close ends the stream.
+func (f *FastImportWriter) Close() error {
+	if !f.started {
+		fmt.Fprintf(f.w, "feature done\n")
+	}
+	f.w.WriteString("done\n")
+	return f.w.Flush()
+}
+
+func writeFastImportData(w *bufio.Writer, data string) {
+	fmt.Fprintf(w, "data %d\n%s\n", len(data), data)
+}
+
+func fastImportIdent(email string, when time.Time) string {
+	clean := strings.NewReplacer("<", "", ">", "", "\n", " ")
+	email = clean.Replace(email)
+	name := email
+	if at := strings.Index(email, "@"); at > 0 {
+		name = email[:at]
+	}
+	if name == "" {
+		name, email = "unknown", "unknown"
+	}
+	return fmt.Sprintf("%s <%s> %d %s", name, email, when.Unix(), when.Format("-0700"))
+}
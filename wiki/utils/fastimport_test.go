@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestFastImportWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFastImportWriter(&buf, "refs/heads/main")
+	when := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	err := w.Commit(FastImportCommit{
+		Author:  "jdoe@trevecca.edu",
+		When:    when,
+		Message: "Create Dan Boone\n",
+		Files:   []FastImportFile{{Path: "pages/dan-boone.md", Content: "# Dan Boone\n"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = w.Commit(FastImportCommit{
+		When:    when.Add(time.Hour),
+		Message: "Rename\n",
+		Deletes: []string{"pages/dan-boone.md"},
+		Files:   []FastImportFile{{Path: "pages/daniel-boone.md", Content: "# Daniel Boone\n"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "feature done\n" +
+		"reset refs/heads/main\n\n" +
+		"commit refs/heads/main\nmark :1\n" +
+		"author jdoe <jdoe@trevecca.edu> 1740830400 +0000\n" +
+		"committer jdoe <jdoe@trevecca.edu> 1740830400 +0000\n" +
+		"data 17\nCreate Dan Boone\n\n" +
+		"M 100644 inline pages/dan-boone.md\ndata 12\n# Dan Boone\n\n\n" +
+		"commit refs/heads/main\nmark :2\n" +
+		"author unknown <unknown> 1740834000 +0000\n" +
+		"committer unknown <unknown> 1740834000 +0000\n" +
+		"data 7\nRename\n\n" +
+		"from :1\n" +
+		"D pages/dan-boone.md\n" +
+		"M 100644 inline pages/daniel-boone.md\ndata 15\n# Daniel Boone\n\n\n" +
+		"done\n"
+	if buf.String() != want {
+		t.Errorf("stream =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ImportPage is a page read from another wiki, ready to be created here.
+// Categories are slug paths such as "people/faculty".
+type ImportPage struct {
+	Source		string
+	Slug		string
+	Name		string
+	Categories	[]string
+	Revisions	[]ImportRevision
+}
+
+// ImportRevision is one version of an imported page, oldest first.
+type ImportRevision struct {
+	Author		string
+	DateTime	time.Time
+	Summary		string
+	Minor		bool
+	Content		string
+}
+
+// Slugify turns a title or file name into a page or category slug:
+// lowercase letters and digits separated by single hyphens.
+func Slugify(s string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9'):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+		case r == '\'' || r == '’':
+			// "Boone's" is "boones", not "boone-s"
+		default:
+			hyphen = true
+		}
+	}
+	return b.String()
+}
+
+// titleFromSlug turns "student-life" into "Student Life".
+func titleFromSlug(slug string) string {
+	words := strings.Split(slug, "-")
+	for i, w := range words {
+		if w != "" {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+type mediaWikiPage struct {
+	Title		string				`xml:"title"`
+	Namespace	int					`xml:"ns"`
+	Redirect	*struct{}			`xml:"redirect"`
+	Revisions	[]mediaWikiRevision	`xml:"revision"`
+}
+
+type mediaWikiRevision struct {
+	Timestamp	time.Time	`xml:"timestamp"`
+	Username	string		`xml:"contributor>username"`
+	IP			string		`xml:"contributor>ip"`
+	Comment		string		`xml:"comment"`
+	Minor		*struct{}	`xml:"minor"`
+	Text		string		`xml:"text"`
+}
+
+// ParseMediaWikiDump reads the articles of a MediaWiki XML export with their
+// full revision history. Pages outside the main namespace and redirects are
+// skipped. Wikitext is converted to markdown, and the categories of a page
+// come from the [[Category:...]] links in its latest revision. Authors are
+// MediaWiki user names (or IP addresses), with emailDomain appended when set.
+func ParseMediaWikiDump(r io.Reader, emailDomain string) ([]ImportPage, error) {
+	var pages []ImportPage
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading dump: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "page" {
+			continue
+		}
+		var mwPage mediaWikiPage
+		err = dec.DecodeElement(&mwPage, &start)
+		if err != nil {
+			return nil, fmt.Errorf("reading dump: %w", err)
+		}
+		if mwPage.Namespace != 0 || mwPage.Redirect != nil || len(mwPage.Revisions) == 0 {
+			continue
+		}
+
+		page := ImportPage{
+			Source: mwPage.Title,
+			Slug:   Slugify(mwPage.Title),
+			Name:   mwPage.Title,
+		}
+		sort.SliceStable(mwPage.Revisions, func(i, j int) bool {
+			return mwPage.Revisions[i].Timestamp.Before(mwPage.Revisions[j].Timestamp)
+		})
+		for _, rev := range mwPage.Revisions {
+			author := rev.Username
+			if author == "" {
+				author = rev.IP
+			}
+			if emailDomain != "" && rev.Username != "" {
+				author = strings.ToLower(strings.ReplaceAll(rev.Username, " ", ".")) + "@" + emailDomain
+			}
+			// summaries longer than ours are cut short rather than refused
+			summary := strings.Join(strings.Fields(rev.Comment), " ")
+			if runes := []rune(summary); len(runes) > MaxSummaryLength {
+				summary = string(runes[:MaxSummaryLength])
+			}
+			page.Revisions = append(page.Revisions, ImportRevision{
+				Author:   author,
+				DateTime: rev.Timestamp,
+				Summary:  summary,
+				Minor:    rev.Minor != nil,
+				Content:  WikitextToMarkdown(rev.Text),
+			})
+		}
+		latest := mwPage.Revisions[len(mwPage.Revisions)-1].Text
+		for _, m := range wikiCategoryLink.FindAllStringSubmatch(latest, -1) {
+			page.Categories = append(page.Categories, Slugify(m[1]))
+		}
+		pages = append(pages, page)
+	}
+	return pages, nil
+}
+
+var (
+	wikiCategoryLink	= regexp.MustCompile(`(?i)\[\[\s*category\s*:\s*([^\]|]+?)\s*(?:\|[^\]]*)?\]\]\n?`)
+	wikiHeading			= regexp.MustCompile(`^(={1,6})\s*(.*?)\s*={1,6}\s*$`)
+	wikiInternalLink	= regexp.MustCompile(`\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	wikiExternalLink	= regexp.MustCompile(`\[(https?://[^\s\]]+)\s+([^\]]+)\]`)
+	wikiBold			= regexp.MustCompile(`'''(.+?)'''`)
+	wikiItalic			= regexp.MustCompile(`''(.+?)''`)
+	wikiListItem		= regexp.MustCompile(`^([*#]+)\s*(.*)$`)
+)
+
+// WikitextToMarkdown converts the common parts of MediaWiki markup: headings,
+// bold and italic, bullet and numbered lists, and internal and external
+// links. Internal links point at the slug the linked page would be imported
+// under. Category links are dropped, since they become page categories.
+// Anything else (templates, tables) is left as it is.
+func WikitextToMarkdown(text string) string {
+	text = wikiCategoryLink.ReplaceAllString(text, "")
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if m := wikiHeading.FindStringSubmatch(line); m != nil {
+			line = strings.Repeat("#", len(m[1])) + " " + m[2]
+		} else if m := wikiListItem.FindStringSubmatch(line); m != nil {
+			depth := len(m[1]) - 1
+			marker := "-"
+			if strings.HasSuffix(m[1], "#") {
+				marker = "1."
+			}
+			line = strings.Repeat("  ", depth) + marker + " " + m[2]
+		}
+		line = wikiInternalLink.ReplaceAllStringFunc(line, func(link string) string {
+			m := wikiInternalLink.FindStringSubmatch(link)
+			target, label := strings.TrimSpace(m[1]), m[2]
+			if label == "" {
+				label = target
+			}
+			return fmt.Sprintf("[%s](/pages/%s)", label, Slugify(target))
+		})
+		line = wikiExternalLink.ReplaceAllString(line, "[$2]($1)")
+		line = wikiBold.ReplaceAllString(line, "**$1**")
+		line = wikiItalic.ReplaceAllString(line, "*$1*")
+		lines[i] = line
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+// ReadMarkdownDir reads every .md file under fsys as a page with a single
+// revision by author, dated by the file's modification time. The page is
+// named by the file's first "# " heading, or else its file name, and the
+// directories it sits in become its category: people/faculty/dan-boone.md is
+// the page dan-boone in category people/faculty.
+func ReadMarkdownDir(fsys fs.FS, author string) ([]ImportPage, error) {
+	var pages []ImportPage
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != ".md" {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		base := strings.TrimSuffix(path.Base(p), ".md")
+		page := ImportPage{
+			Source: p,
+			Slug:   Slugify(base),
+			Name:   markdownTitle(string(content)),
+			Revisions: []ImportRevision{{
+				Author:   author,
+				DateTime: info.ModTime(),
+				Content:  string(content),
+			}},
+		}
+		if page.Name == "" {
+			page.Name = titleFromSlug(page.Slug)
+		}
+		if dir := path.Dir(p); dir != "." {
+			var parts []string
+			for _, part := range strings.Split(dir, "/") {
+				parts = append(parts, Slugify(part))
+			}
+			page.Categories = []string{strings.Join(parts, "/")}
+		}
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+func markdownTitle(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if title, ok := strings.CutPrefix(line, "# "); ok {
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const mediaWikiDump = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.11/" version="0.11">
+  <siteinfo><sitename>Old Wiki</sitename></siteinfo>
+  <page>
+    <title>Dan Boone</title>
+    <ns>0</ns>
+    <id>1</id>
+    <revision>
+      <id>11</id>
+      <timestamp>2012-05-02T10:00:00Z</timestamp>
+      <contributor><username>Jane Doe</username><id>3</id></contributor>
+      <minor />
+      <comment>Add   early life</comment>
+      <text xml:space="preserve">== Life ==
+Dan was '''born''' in [[Kentucky|the bluegrass state]].
+[[Category:Faculty]]</text>
+    </revision>
+    <revision>
+      <id>10</id>
+      <timestamp>2012-05-01T09:00:00Z</timestamp>
+      <contributor><ip>10.0.0.1</ip></contributor>
+      <text xml:space="preserve">Stub.</text>
+    </revision>
+  </page>
+  <page>
+    <title>Boone</title>
+    <ns>0</ns>
+    <redirect title="Dan Boone" />
+    <revision><timestamp>2012-05-03T00:00:00Z</timestamp><text>#REDIRECT [[Dan Boone]]</text></revision>
+  </page>
+  <page>
+    <title>Talk:Dan Boone</title>
+    <ns>1</ns>
+    <revision><timestamp>2012-05-03T00:00:00Z</timestamp><text>chatter</text></revision>
+  </page>
+</mediawiki>`
+
+func TestParseMediaWikiDump(t *testing.T) {
+	pages, err := ParseMediaWikiDump(strings.NewReader(mediaWikiDump), "trevecca.edu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages, want 1 (redirects and talk pages skipped)", len(pages))
+	}
+	page := pages[0]
+	if page.Slug != "dan-boone" || page.Name != "Dan Boone" {
+		t.Errorf("got slug %q name %q", page.Slug, page.Name)
+	}
+	if len(page.Categories) != 1 || page.Categories[0] != "faculty" {
+		t.Errorf("got categories %v, want [faculty]", page.Categories)
+	}
+	if len(page.Revisions) != 2 {
+		t.Fatalf("got %d revisions, want 2", len(page.Revisions))
+	}
+
+	first, second := page.Revisions[0], page.Revisions[1]
+	if first.Author != "10.0.0.1" || !first.DateTime.Equal(time.Date(2012, 5, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("first revision by %q at %v", first.Author, first.DateTime)
+	}
+	if second.Author != "jane.doe@trevecca.edu" {
+		t.Errorf("second revision author = %q", second.Author)
+	}
+	if second.Summary != "Add early life" || !second.Minor || first.Summary != "" || first.Minor {
+		t.Errorf("got summaries %q (minor %v) and %q (minor %v)", first.Summary, first.Minor, second.Summary, second.Minor)
+	}
+	want := "## Life\nDan was **born** in [the bluegrass state](/pages/kentucky).\n"
+	if second.Content != want {
+		t.Errorf("second revision content = %q, want %q", second.Content, want)
+	}
+}
+
+func TestWikitextToMarkdown(t *testing.T) {
+	in := "* one\n** nested ''item''\n# first\nSee [https://trevecca.edu Trevecca] and [[Student Life]]."
+	want := "- one\n  - nested *item*\n1. first\nSee [Trevecca](https://trevecca.edu) and [Student Life](/pages/student-life).\n"
+	if got := WikitextToMarkdown(in); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestReadMarkdownDir(t *testing.T) {
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"people/faculty/dan-boone.md": {Data: []byte("# Daniel Boone\n\nExplorer.\n"), ModTime: modTime},
+		"Student Life.md":             {Data: []byte("No heading here.\n"), ModTime: modTime},
+		"notes.txt":                   {Data: []byte("ignored")},
+	}
+	pages, err := ReadMarkdownDir(fsys, "importer@trevecca.edu")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("got %d pages, want 2", len(pages))
+	}
+	bySlug := map[string]ImportPage{}
+	for _, p := range pages {
+		bySlug[p.Slug] = p
+	}
+
+	boone := bySlug["dan-boone"]
+	if boone.Name != "Daniel Boone" || len(boone.Categories) != 1 || boone.Categories[0] != "people/faculty" {
+		t.Errorf("got dan-boone name %q categories %v", boone.Name, boone.Categories)
+	}
+	if rev := boone.Revisions[0]; rev.Author != "importer@trevecca.edu" || !rev.DateTime.Equal(modTime) {
+		t.Errorf("got revision by %q at %v", rev.Author, rev.DateTime)
+	}
+
+	life := bySlug["student-life"]
+	if life.Name != "Student Life" || len(life.Categories) != 0 {
+		t.Errorf("got student-life name %q categories %v", life.Name, life.Categories)
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	for in, want := range map[string]string{
+		"Dan Boone":           "dan-boone",
+		"Boone's  Cabin (old)": "boones-cabin-old",
+		"--Already-a-slug--":  "already-a-slug",
+	} {
+		if got := Slugify(in); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// [[Page Name]], [[slug|label]] and [[slug#section|label]]
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]|#]+)(?:#[^\[\]|]*)?(?:\|[^\[\]]*)?\]\]`)
+	// [label](/pages/slug), with an optional #section or ?query
+	pageLinkPattern = regexp.MustCompile(`\]\(\s*/pages/([a-z0-9]+(?:-[a-z0-9]+)*)(?:[#?][^)\s]*)?\s*\)`)
+	fencedCode      = regexp.MustCompile("(?ms)^\\s*(```|~~~).*?^\\s*(```|~~~)\\s*$")
+	inlineCode      = regexp.MustCompile("`[^`\n]+`")
+)
+
+// ParseWikiLinks returns the slugs of the pages a page's markdown links to,
+// sorted and without duplicates. Both [[Page Name]] links, whose target is
+// slugified, and markdown links to /pages/<slug> count. Links inside code
+// don't.
+func ParseWikiLinks(content string) []string {
+	content = fencedCode.ReplaceAllString(content, "")
+	content = inlineCode.ReplaceAllString(content, "")
+
+	var slugs []string
+	for _, m := range wikiLinkPattern.FindAllStringSubmatch(content, -1) {
+		if slug := Slugify(strings.TrimSpace(m[1])); slug != "" {
+			slugs = append(slugs, slug)
+		}
+	}
+	for _, m := range pageLinkPattern.FindAllStringSubmatch(content, -1) {
+		// /pages/new is the editor, not a page
+		if m[1] != "new" {
+			slugs = append(slugs, m[1])
+		}
+	}
+	slices.Sort(slugs)
+	return slices.Compact(slugs)
+}
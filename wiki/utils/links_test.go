@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseWikiLinks(t *testing.T) {
+	content := "See [[Dan Boone]] and [[student-life|campus life]], or [[Dan Boone#Early life|his youth]].\n" +
+		"The [mission](/pages/mission-and-goals) and [history](/pages/history#founding).\n" +
+		"Not [external](https://example.com/pages/nope), not [new](/pages/new), not `[[Code]]`.\n" +
+		"```\n[[Fenced]]\n```\n"
+
+	got := ParseWikiLinks(content)
+	want := []string{"dan-boone", "history", "mission-and-goals", "student-life"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseWikiLinksNone(t *testing.T) {
+	if got := ParseWikiLinks("no links [here] or [[]]"); len(got) != 0 {
+		t.Errorf("got %v, want none", got)
+	}
+}
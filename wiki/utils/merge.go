@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff/lcs"
+	"github.com/google/uuid"
+)
+
+// MergeConflict is a region that both the current revision and the submitted
+// content changed in different ways since the base revision.
+type MergeConflict struct {
+	Line		int			`json:"line"`
+	Base		string		`json:"base"`
+	Current		string		`json:"current"`
+	Yours		string		`json:"yours"`
+}
+
+// MergeConflictError is returned when a revision was made against an older
+// base revision and could not be merged cleanly into the current content.
+type MergeConflictError struct {
+	BaseRevision	uuid.UUID		`json:"base_revision"`
+	CurrentRevision	uuid.UUID		`json:"current_revision"`
+	Merged			string			`json:"merged"`
+	Conflicts		[]MergeConflict	`json:"conflicts"`
+}
+
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("%d conflicting hunk(s) merging against revision %s", len(e.Conflicts), e.CurrentRevision)
+}
+
+// lineChange replaces base[start:end] with lines.
+type lineChange struct {
+	start	int
+	end		int
+	lines	[]string
+}
+
+// splitLines splits content into lines, keeping the trailing newline on each.
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines computes line-level changes that turn a into b. Each distinct line
+// is mapped to a single rune so the rune-level LCS diff works on whole lines.
+func diffLines(a, b []string) []lineChange {
+	ids := make(map[string]rune)
+	toRunes := func(lines []string) []rune {
+		rs := make([]rune, len(lines))
+		for i, l := range lines {
+			id, ok := ids[l]
+			if !ok {
+				id = rune(len(ids))
+				ids[l] = id
+			}
+			rs[i] = id
+		}
+		return rs
+	}
+	ra, rb := toRunes(a), toRunes(b)
+
+	diffs := lcs.DiffRunes(ra, rb)
+	changes := make([]lineChange, len(diffs))
+	for i, d := range diffs {
+		changes[i] = lineChange{d.Start, d.End, b[d.ReplStart:d.ReplEnd]}
+	}
+	return changes
+}
+
+// applyChanges returns base[lo:hi] with the given changes (which must lie within
+// that range, in order) applied.
+func applyChanges(base []string, lo, hi int, changes []lineChange) []string {
+	var out []string
+	pos := lo
+	for _, ch := range changes {
+		out = append(out, base[pos:ch.start]...)
+		out = append(out, ch.lines...)
+		pos = ch.end
+	}
+	return append(out, base[pos:hi]...)
+}
+
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBlock writes lines to sb, making sure the block ends in a newline so a
+// following conflict marker starts on its own line.
+func writeBlock(sb *strings.Builder, lines []string) {
+	text := strings.Join(lines, "")
+	sb.WriteString(text)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		sb.WriteString("\n")
+	}
+}
+
+// MergeContent performs a three-way merge of the current and submitted
+// ("yours") content against their common base. Changes made on only one side
+// are taken as-is; overlapping changes that differ are reported as conflicts,
+// and the returned content contains git-style conflict markers for them.
+func MergeContent(base, current, yours string) (string, []MergeConflict) {
+	baseLines := splitLines(base)
+	theirs := diffLines(baseLines, splitLines(current))
+	ours := diffLines(baseLines, splitLines(yours))
+
+	var sb strings.Builder
+	var conflicts []MergeConflict
+	pos := 0
+	ti, oi := 0, 0
+	for ti < len(theirs) || oi < len(ours) {
+		// start a cluster with whichever change comes first
+		var lo, hi int
+		if oi >= len(ours) || (ti < len(theirs) && theirs[ti].start <= ours[oi].start) {
+			lo, hi = theirs[ti].start, theirs[ti].end
+		} else {
+			lo, hi = ours[oi].start, ours[oi].end
+		}
+		// absorb every change from either side that overlaps the cluster;
+		// insertions touching its edge count as overlapping
+		tStart, oStart := ti, oi
+		for {
+			grew := false
+			for ti < len(theirs) && overlaps(theirs[ti], lo, hi) {
+				hi = max(hi, theirs[ti].end)
+				ti++
+				grew = true
+			}
+			for oi < len(ours) && overlaps(ours[oi], lo, hi) {
+				hi = max(hi, ours[oi].end)
+				oi++
+				grew = true
+			}
+			if !grew {
+				break
+			}
+		}
+
+		writeBlock(&sb, baseLines[pos:lo])
+		pos = hi
+
+		currentLines := applyChanges(baseLines, lo, hi, theirs[tStart:ti])
+		yourLines := applyChanges(baseLines, lo, hi, ours[oStart:oi])
+		switch {
+		case tStart == ti:
+			writeBlock(&sb, yourLines)
+		case oStart == oi:
+			writeBlock(&sb, currentLines)
+		case sameLines(currentLines, yourLines):
+			writeBlock(&sb, currentLines)
+		default:
+			conflicts = append(conflicts, MergeConflict{
+				Line:    lo + 1,
+				Base:    strings.Join(baseLines[lo:hi], ""),
+				Current: strings.Join(currentLines, ""),
+				Yours:   strings.Join(yourLines, ""),
+			})
+			sb.WriteString("<<<<<<< current\n")
+			writeBlock(&sb, currentLines)
+			sb.WriteString("=======\n")
+			writeBlock(&sb, yourLines)
+			sb.WriteString(">>>>>>> yours\n")
+		}
+	}
+	sb.WriteString(strings.Join(baseLines[pos:], ""))
+
+	return sb.String(), conflicts
+}
+
+func overlaps(ch lineChange, lo, hi int) bool {
+	if ch.start < hi {
+		return true
+	}
+	// an insertion at the edge of the cluster, or a change at the point of an
+	// insertion, can't be ordered relative to the other side
+	return ch.start == hi && (ch.start == ch.end || lo == hi)
+}
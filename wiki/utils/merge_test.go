@@ -0,0 +1,76 @@
+package utils
+
+import "testing"
+
+func TestMergeContentNonOverlapping(t *testing.T) {
+	base := "one\ntwo\nthree\nfour\nfive\n"
+	current := "ONE\ntwo\nthree\nfour\nfive\n"
+	yours := "one\ntwo\nthree\nfour\nFIVE\nsix\n"
+
+	merged, conflicts := MergeContent(base, current, yours)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %d", len(conflicts))
+	}
+	want := "ONE\ntwo\nthree\nfour\nFIVE\nsix\n"
+	if merged != want {
+		t.Errorf("merged = %q, want %q", merged, want)
+	}
+}
+
+func TestMergeContentIdenticalChanges(t *testing.T) {
+	base := "a\nb\nc\n"
+	edited := "a\nB\nc\n"
+
+	merged, conflicts := MergeContent(base, edited, edited)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %d", len(conflicts))
+	}
+	if merged != edited {
+		t.Errorf("merged = %q, want %q", merged, edited)
+	}
+}
+
+func TestMergeContentConflict(t *testing.T) {
+	base := "a\nb\nc\n"
+	current := "a\nfrom current\nc\n"
+	yours := "a\nfrom yours\nc\n"
+
+	merged, conflicts := MergeContent(base, current, yours)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+	c := conflicts[0]
+	if c.Line != 2 || c.Base != "b\n" || c.Current != "from current\n" || c.Yours != "from yours\n" {
+		t.Errorf("unexpected conflict: %+v", c)
+	}
+	want := "a\n<<<<<<< current\nfrom current\n=======\nfrom yours\n>>>>>>> yours\nc\n"
+	if merged != want {
+		t.Errorf("merged = %q, want %q", merged, want)
+	}
+}
+
+func TestMergeContentInsertionsAtSamePoint(t *testing.T) {
+	base := "a\nb\n"
+	current := "a\nx\nb\n"
+	yours := "a\ny\nb\n"
+
+	_, conflicts := MergeContent(base, current, yours)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+}
+
+func TestMergeContentMissingTrailingNewline(t *testing.T) {
+	base := "a\nb"
+	current := "a\nc"
+	yours := "a\nd"
+
+	merged, conflicts := MergeContent(base, current, yours)
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %d", len(conflicts))
+	}
+	want := "a\n<<<<<<< current\nc\n=======\nd\n>>>>>>> yours\n"
+	if merged != want {
+		t.Errorf("merged = %q, want %q", merged, want)
+	}
+}
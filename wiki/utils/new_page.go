@@ -3,15 +3,14 @@ package utils
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"os"
-	"path/filepath"
+	"wiki/database"
+	"wiki/filesystem"
 
 	"github.com/aymanbagabas/go-udiff"
 	"github.com/google/uuid"
 )
 
-func CreateNewPage(ctx context.Context, db *sql.DB, dataDir string, req NewPageRequest) error {
+func CreateNewPage(ctx context.Context, db *sql.DB, store filesystem.Storage, req NewPageRequest) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -32,12 +31,16 @@ func CreateNewPage(ctx context.Context, db *sql.DB, dataDir string, req NewPageR
 	}
 
 	// create revision db entry
+	summary := req.Summary
+	if summary == "" {
+		summary = SummaryCreated
+	}
 	var revId uuid.UUID
 	err = tx.QueryRowContext(ctx, `
-		INSERT INTO revisions (page_id, author, slug, name, archive_date)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO revisions (page_id, author, slug, name, archive_date, summary, date_time)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, now()))
 		RETURNING uuid;
-	`, pageId, req.Author, req.Slug, req.Name, req.ArchiveDate).Scan(&revId)
+	`, pageId, req.Author, req.Slug, req.Name, req.ArchiveDate, summary, req.DateTime).Scan(&revId)
 	if err != nil {
 		return err
 	}
@@ -53,40 +56,37 @@ func CreateNewPage(ctx context.Context, db *sql.DB, dataDir string, req NewPageR
 		return err
 	}
 
-	// FILE STUFF
-	pagePath := filepath.Join(dataDir, "pages", fmt.Sprintf("%s.md", req.Slug))
-	revPath := filepath.Join(dataDir, "revisions", fmt.Sprintf("%s_%s.txt", req.Slug, revId))
-	snapPath := filepath.Join(dataDir, "snapshots", fmt.Sprintf("%s_%s.md", req.Slug, snapId))
-
-	err = os.MkdirAll(filepath.Join(dataDir, "pages"), 0755)
+	err = database.SetPageLinks(ctx, tx, pageId, ParseWikiLinks(req.Content))
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(filepath.Join(dataDir, "revisions"), 0755)
+	err = database.SetPageTemplates(ctx, tx, pageId, ParseTemplateIncludes(req.Content))
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(filepath.Join(dataDir, "snapshots"), 0755)
+	// pages that included the template before it existed
+	err = database.MarkTemplateIncluders(ctx, tx, pageId)
 	if err != nil {
 		return err
 	}
 
-	diff := udiff.Unified(pagePath, pagePath, "", req.Content)
+	// FILE STUFF
+	pageFilename := filesystem.GetPageFilename(pageId)
+	diff := udiff.Unified(pageFilename, pageFilename, "", req.Content)
 
-	err = os.WriteFile(pagePath, []byte(req.Content), 0644)
+	err = store.PutPage(ctx, pageId, req.Content)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(snapPath, []byte(req.Content), 0644)
+	err = store.PutSnapshot(ctx, pageId, snapId, req.Content)
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(revPath, []byte(diff), 0644)
+	err = store.PutRevision(ctx, pageId, revId, diff)
 	if err != nil {
 		return err
 	}
 
-
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -0,0 +1,10 @@
+package utils
+
+import "wiki/database"
+
+// Inbox is a page of a user's notifications and how many they haven't read
+// in all.
+type Inbox struct {
+	Notifications	[]database.Notification	`json:"notifications"`
+	Unread			int						`json:"unread"`
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"time"
+	"wiki/database"
 
 	"github.com/google/uuid"
 )
@@ -15,6 +16,9 @@ type Page struct {
 	LastEdit		*uuid.UUID	`json:"last_edit"`
 	LastEditTime	*time.Time	`json:"last_edit_time"`
 	Content			string		`json:"content"`
+	RedirectedFrom	string		`json:"redirected_from,omitempty"`
+	MissingLinks	[]string	`json:"missing_links"`
+	Protection		*database.Protection	`json:"protection,omitempty"`
 }
 
 type PageInfoPrev struct {
@@ -40,6 +44,10 @@ type NewPageRequest struct {
 	Author			string		`json:"author"`
 	ArchiveDate		*time.Time	`json:"archive_date"`
 	Content			string		`json:"content"`
+	// Summary of the first revision; SummaryCreated if empty
+	Summary			string		`json:"-"`
+	// DateTime backdates the first revision, for imports; nil means now
+	DateTime		*time.Time	`json:"-"`
 }
 
 type DeletePageRequest struct {
@@ -47,3 +55,31 @@ type DeletePageRequest struct {
 	User			string		`json:"user"`
 }
 
+
+type RestorePageRequest struct {
+	Slug			string		`json:"slug"`
+	User			string		`json:"user"`
+}
+
+type DeletedPage struct {
+	UUID			uuid.UUID	`json:"uuid"`
+	Slug			string		`json:"slug"`
+	Name			string		`json:"name"`
+	DeletedAt		time.Time	`json:"deleted_at"`
+	DeletedBy		string		`json:"deleted_by"`
+}
+
+// WantedPage is a slug that pages link to but no page has, with the number
+// of pages linking to it.
+type WantedPage struct {
+	Slug	string	`json:"slug"`
+	Links	int		`json:"links"`
+}
+
+// DeletedLink is a link from a page to a page that's been deleted.
+type DeletedLink struct {
+	Page		database.PageLink	`json:"page"`
+	TargetSlug	string				`json:"target_slug"`
+	Target		database.PageLink	`json:"target"`
+	DeletedAt	time.Time			`json:"deleted_at"`
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"wiki/database"
+	wikierrors "wiki/errors"
+)
+
+// Protection levels. A page without protection is open to all contributors.
+const (
+	ProtectionOpen      = "open"
+	ProtectionSemi      = "semi"
+	ProtectionModerator = "moderator"
+	ProtectionLocked    = "locked"
+)
+
+// Editor is who's making an edit, as passed on by the API layer from their
+// token.
+type Editor struct {
+	Roles          []string
+	AccountCreated *time.Time
+}
+
+// ParseEditor reads an editor from the comma-separated roles and the
+// RFC 3339 account creation time the API layer sends with an edit. A
+// missing or bad time leaves AccountCreated nil.
+func ParseEditor(roles string, accountCreated string) Editor {
+	var editor Editor
+	if roles != "" {
+		editor.Roles = strings.Split(roles, ",")
+	}
+	if created, err := time.Parse(time.RFC3339, accountCreated); err == nil {
+		editor.AccountCreated = &created
+	}
+	return editor
+}
+
+func (e Editor) isModerator() bool {
+	return slices.Contains(e.Roles, "moderator") || slices.Contains(e.Roles, "admin")
+}
+
+// CheckProtection returns a PageProtected error if the editor can't edit a
+// page with protection p at now. p is nil for unprotected pages.
+func CheckProtection(p *database.Protection, editor Editor, now time.Time) error {
+	if p == nil || (p.ExpiresAt != nil && !p.ExpiresAt.After(now)) {
+		return nil
+	}
+	switch p.Level {
+	case ProtectionLocked:
+		return wikierrors.PageProtected("this page is locked")
+	case ProtectionModerator:
+		if !editor.isModerator() {
+			return wikierrors.PageProtected("only moderators can edit this page")
+		}
+	case ProtectionSemi:
+		if editor.isModerator() {
+			return nil
+		}
+		minAge := time.Duration(p.MinAccountDays) * 24 * time.Hour
+		if editor.AccountCreated == nil || now.Sub(*editor.AccountCreated) < minAge {
+			return wikierrors.PageProtected(fmt.Sprintf("only accounts at least %d days old can edit this page", p.MinAccountDays))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	"wiki/database"
+	wikierrors "wiki/errors"
+)
+
+func TestCheckProtection(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	yesterday := now.Add(-24 * time.Hour)
+	lastMonth := now.AddDate(0, -1, 0)
+
+	contributor := Editor{Roles: []string{"contributor"}, AccountCreated: &lastMonth}
+	newContributor := Editor{Roles: []string{"contributor"}, AccountCreated: &yesterday}
+	moderator := Editor{Roles: []string{"contributor", "moderator"}, AccountCreated: &yesterday}
+	oldToken := Editor{Roles: []string{"contributor"}}
+
+	semi := &database.Protection{Level: ProtectionSemi, MinAccountDays: 7}
+	mod := &database.Protection{Level: ProtectionModerator}
+	locked := &database.Protection{Level: ProtectionLocked}
+	expired := &database.Protection{Level: ProtectionLocked, ExpiresAt: &yesterday}
+
+	tests := []struct {
+		name       string
+		protection *database.Protection
+		editor     Editor
+		allowed    bool
+	}{
+		{"open", nil, newContributor, true},
+		{"semi, old account", semi, contributor, true},
+		{"semi, new account", semi, newContributor, false},
+		{"semi, unknown account age", semi, oldToken, false},
+		{"semi, moderator", semi, moderator, true},
+		{"moderator-only, contributor", mod, contributor, false},
+		{"moderator-only, moderator", mod, moderator, true},
+		{"locked, moderator", locked, moderator, false},
+		{"expired", expired, newContributor, true},
+	}
+	for _, tt := range tests {
+		err := CheckProtection(tt.protection, tt.editor, now)
+		if tt.allowed && err != nil {
+			t.Errorf("%s: got %v, want allowed", tt.name, err)
+		}
+		if !tt.allowed {
+			werr, ok := wikierrors.AsWikiError(err)
+			if !ok || werr.Code != 403 {
+				t.Errorf("%s: got %v, want a 403", tt.name, err)
+			}
+		}
+	}
+}
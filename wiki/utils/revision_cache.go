@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRevisionCacheBytes is the revision cache size used when
+// WIKI_REVISION_CACHE_BYTES isn't set.
+const DefaultRevisionCacheBytes = 64 << 20
+
+// RevisionCache is a least-recently-used cache of page content rebuilt at a
+// revision, keyed by revision ID. Revisions never change once written, so
+// entries are only ever evicted, never invalidated. It's bounded by the total
+// size of the cached content; a limit of zero or less turns it off.
+type RevisionCache struct {
+	mu			sync.Mutex
+	maxBytes	int64
+	bytes		int64
+	entries		map[uuid.UUID]*list.Element
+	order		*list.List
+	hits		uint64
+	misses		uint64
+}
+
+type revisionCacheEntry struct {
+	revId	uuid.UUID
+	content	string
+}
+
+type RevisionCacheStats struct {
+	Entries		int		`json:"entries"`
+	Bytes		int64	`json:"bytes"`
+	MaxBytes	int64	`json:"max_bytes"`
+	Hits		uint64	`json:"hits"`
+	Misses		uint64	`json:"misses"`
+}
+
+func NewRevisionCache(maxBytes int64) *RevisionCache {
+	return &RevisionCache{
+		maxBytes: maxBytes,
+		entries:  make(map[uuid.UUID]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (c *RevisionCache) Get(revId uuid.UUID) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[revId]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*revisionCacheEntry).content, true
+}
+
+// Put caches content for revId, evicting the least recently used entries to
+// stay within the size limit. Content bigger than the whole limit isn't cached.
+func (c *RevisionCache) Put(revId uuid.UUID, content string) {
+	size := int64(len(content))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if size > c.maxBytes {
+		return
+	}
+	if elem, ok := c.entries[revId]; ok {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[revId] = c.order.PushFront(&revisionCacheEntry{revId: revId, content: content})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		entry := c.order.Remove(oldest).(*revisionCacheEntry)
+		delete(c.entries, entry.revId)
+		c.bytes -= int64(len(entry.content))
+	}
+}
+
+func (c *RevisionCache) Stats() RevisionCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return RevisionCacheStats{
+		Entries:  len(c.entries),
+		Bytes:    c.bytes,
+		MaxBytes: c.maxBytes,
+		Hits:     c.hits,
+		Misses:   c.misses,
+	}
+}
+
+var revisionCache = NewRevisionCache(DefaultRevisionCacheBytes)
+
+// SetRevisionCache replaces the cache used by GetContentAtRevision.
+func SetRevisionCache(c *RevisionCache) {
+	revisionCache = c
+}
+
+func GetRevisionCacheStats() RevisionCacheStats {
+	return revisionCache.Stats()
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRevisionCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewRevisionCache(30)
+	a, b, c := uuid.New(), uuid.New(), uuid.New()
+	cache.Put(a, strings.Repeat("a", 10))
+	cache.Put(b, strings.Repeat("b", 10))
+	cache.Put(c, strings.Repeat("c", 10))
+
+	// touch a so that b is the oldest
+	if _, ok := cache.Get(a); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.Put(uuid.New(), strings.Repeat("d", 10))
+
+	if _, ok := cache.Get(b); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, id := range []uuid.UUID{a, c} {
+		if _, ok := cache.Get(id); !ok {
+			t.Errorf("expected %s to still be cached", id)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.Entries != 3 || stats.Bytes != 30 {
+		t.Errorf("got %d entries, %d bytes; want 3, 30", stats.Entries, stats.Bytes)
+	}
+	if stats.Hits != 3 || stats.Misses != 1 {
+		t.Errorf("got %d hits, %d misses; want 3, 1", stats.Hits, stats.Misses)
+	}
+}
+
+func TestRevisionCacheSkipsOversizedContent(t *testing.T) {
+	cache := NewRevisionCache(5)
+	id := uuid.New()
+	cache.Put(id, "too long")
+	if _, ok := cache.Get(id); ok {
+		t.Error("expected content over the limit not to be cached")
+	}
+
+	disabled := NewRevisionCache(0)
+	disabled.Put(id, "x")
+	if _, ok := disabled.Get(id); ok {
+		t.Error("expected a zero-size cache to cache nothing")
+	}
+}
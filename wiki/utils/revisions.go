@@ -1,7 +1,11 @@
 package utils
 
 import (
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
+	wikierrors "wiki/errors"
 
 	"github.com/google/uuid"
 )
@@ -15,6 +19,9 @@ type Revision struct {
 	Name			string		`json:"name"`
 	ArchiveDate		*time.Time	`json:"archive_date"`
 	DeletedAt		*time.Time	`json:"deleted_at"`
+	RevertedFrom	*uuid.UUID	`json:"reverted_from"`
+	Summary			string		`json:"summary"`
+	Minor			bool		`json:"minor"`
 	Content			string		`json:"content"`
 }
 
@@ -25,6 +32,39 @@ type RevisionRequest struct {
 	Name			string		`json:"name"`
 	ArchiveDate		*time.Time	`json:"archive_date"`
 	DeletedAt		*time.Time	`json:"deleted_at"`
+	BaseRevision	*uuid.UUID	`json:"base_revision"`
+	RevertedFrom	*uuid.UUID	`json:"reverted_from"`
+	// Summary says what the edit changed, up to MaxSummaryLength characters
+	Summary			string		`json:"summary"`
+	Minor			bool		`json:"minor"`
 	NewContent		string		`json:"new_content"`
+	// DateTime backdates the revision, for imports; nil means now
+	DateTime		*time.Time	`json:"-"`
 }
 
+
+// MaxSummaryLength is how long an edit summary can be, in characters.
+const MaxSummaryLength = 500
+
+// Summaries of the revisions the wiki makes itself.
+const (
+	SummaryCreated  = "Created page"
+	SummaryDeleted  = "Deleted page"
+	SummaryRestored = "Restored page"
+)
+
+// RevertSummary is the summary of a revert to a revision by author made at
+// dateTime.
+func RevertSummary(author string, dateTime time.Time) string {
+	return fmt.Sprintf("Reverted to the revision by %s from %s", author, dateTime.Format("2006-01-02 15:04"))
+}
+
+// CleanSummary puts an edit summary on one line, with runs of whitespace
+// collapsed, and checks its length.
+func CleanSummary(summary string) (string, error) {
+	summary = strings.Join(strings.Fields(summary), " ")
+	if utf8.RuneCountInString(summary) > MaxSummaryLength {
+		return "", wikierrors.InvalidSummary(fmt.Sprintf("summary must be at most %d characters", MaxSummaryLength))
+	}
+	return summary, nil
+}
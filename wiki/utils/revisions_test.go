@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCleanSummary(t *testing.T) {
+	got, err := CleanSummary("  Fix   typo\nin intro ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "Fix typo in intro" {
+		t.Errorf("got %q, want the summary on one line", got)
+	}
+
+	if _, err := CleanSummary(strings.Repeat("é", MaxSummaryLength)); err != nil {
+		t.Errorf("summary of %d characters refused: %v", MaxSummaryLength, err)
+	}
+	if _, err := CleanSummary(strings.Repeat("a", MaxSummaryLength+1)); err == nil {
+		t.Errorf("summary over %d characters accepted", MaxSummaryLength)
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 	wikierrors "wiki/errors"
+	"wiki/filesystem"
 
 	"github.com/joho/godotenv"
 )
@@ -102,3 +105,51 @@ func GetDataDir() string {
 	return filepath.Join("..", "wiki-fs")
 }
 
+// GetRevisionCacheBytes returns the revision cache size limit set by
+// WIKI_REVISION_CACHE_BYTES; 0 turns the cache off.
+func GetRevisionCacheBytes() (int64, error) {
+	v := getEnv("WIKI_REVISION_CACHE_BYTES", "")
+	if v == "" {
+		return DefaultRevisionCacheBytes, nil
+	}
+	size, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing WIKI_REVISION_CACHE_BYTES: %w", err)
+	}
+	return size, nil
+}
+
+// GetStorage returns the storage backend selected by WIKI_STORAGE.
+func GetStorage() (filesystem.Storage, error) {
+	return NewStorage(getEnv("WIKI_STORAGE", "local"))
+}
+
+// NewStorage builds the named storage backend, configured from the
+// environment: "local" keeps files under GetDataDir(), "memory" keeps them in
+// memory and loses them on restart, and "s3" uses the WIKI_S3_* bucket.
+func NewStorage(backend string) (filesystem.Storage, error) {
+	switch backend {
+	case "local":
+		return filesystem.NewLocalStorage(GetDataDir()), nil
+	case "memory":
+		return filesystem.NewMemoryStorage(), nil
+	case "s3":
+		config := filesystem.S3Config{
+			Endpoint:  os.Getenv("WIKI_S3_ENDPOINT"),
+			Region:    getEnv("WIKI_S3_REGION", "us-east-1"),
+			Bucket:    os.Getenv("WIKI_S3_BUCKET"),
+			AccessKey: os.Getenv("WIKI_S3_ACCESS_KEY_ID"),
+			SecretKey: os.Getenv("WIKI_S3_SECRET_ACCESS_KEY"),
+		}
+		if config.Endpoint == "" || config.Bucket == "" {
+			return nil, fmt.Errorf("s3 storage needs WIKI_S3_ENDPOINT and WIKI_S3_BUCKET")
+		}
+		cacheTTL, err := time.ParseDuration(getEnv("WIKI_S3_PAGE_CACHE_TTL", "1m"))
+		if err != nil {
+			return nil, fmt.Errorf("parsing WIKI_S3_PAGE_CACHE_TTL: %w", err)
+		}
+		return filesystem.NewS3Storage(config, cacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
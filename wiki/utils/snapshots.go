@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+	"wiki/database"
+	"wiki/filesystem"
+
+	"github.com/google/uuid"
+)
+
+// SnapshotPolicy decides when a page gets a new snapshot, so rebuilding a
+// revision never has to apply too long a chain of diffs. A snapshot is due
+// once any enabled limit is reached since the last one; zero disables a limit.
+type SnapshotPolicy struct {
+	EveryRevisions	int
+	MaxDiffBytes	int64
+	MaxAge			time.Duration
+}
+
+var DefaultSnapshotPolicy = SnapshotPolicy{EveryRevisions: 10}
+
+// GetSnapshotPolicy reads the policy from WIKI_SNAPSHOT_EVERY (revisions),
+// WIKI_SNAPSHOT_DIFF_BYTES (total diff size) and WIKI_SNAPSHOT_MAX_AGE
+// (a duration such as "168h").
+func GetSnapshotPolicy() (SnapshotPolicy, error) {
+	policy := DefaultSnapshotPolicy
+	var err error
+	if v := getEnv("WIKI_SNAPSHOT_EVERY", ""); v != "" {
+		policy.EveryRevisions, err = strconv.Atoi(v)
+		if err != nil {
+			return policy, fmt.Errorf("parsing WIKI_SNAPSHOT_EVERY: %w", err)
+		}
+	}
+	if v := getEnv("WIKI_SNAPSHOT_DIFF_BYTES", ""); v != "" {
+		policy.MaxDiffBytes, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return policy, fmt.Errorf("parsing WIKI_SNAPSHOT_DIFF_BYTES: %w", err)
+		}
+	}
+	if v := getEnv("WIKI_SNAPSHOT_MAX_AGE", ""); v != "" {
+		policy.MaxAge, err = time.ParseDuration(v)
+		if err != nil {
+			return policy, fmt.Errorf("parsing WIKI_SNAPSHOT_MAX_AGE: %w", err)
+		}
+	}
+	if policy.EveryRevisions <= 0 && policy.MaxDiffBytes <= 0 && policy.MaxAge <= 0 {
+		return policy, fmt.Errorf("snapshot policy has no limits set")
+	}
+	return policy, nil
+}
+
+// NeedsSnapshot reports whether a chain of revisions diffs totalling
+// diffBytes, spanning age since the last snapshot, is due a new snapshot.
+func (p SnapshotPolicy) NeedsSnapshot(revisions int, diffBytes int64, age time.Duration) bool {
+	return (p.EveryRevisions > 0 && revisions >= p.EveryRevisions) ||
+		(p.MaxDiffBytes > 0 && diffBytes >= p.MaxDiffBytes) ||
+		(p.MaxAge > 0 && age >= p.MaxAge)
+}
+
+// SnapshotDue reports whether revId should get a snapshot under the policy,
+// given the diffs since the page's most recent snapshot before it.
+func SnapshotDue(ctx context.Context, db *sql.DB, store filesystem.Storage, policy SnapshotPolicy, pageId, revId uuid.UUID) (bool, error) {
+	missingRevs, err := database.GetMissingRevisions(ctx, db, revId)
+	if err != nil {
+		return false, err
+	}
+	if len(missingRevs) == 0 {
+		return false, nil
+	}
+
+	var diffBytes int64
+	if policy.MaxDiffBytes > 0 {
+		for _, r := range missingRevs {
+			revDiff, err := filesystem.GetRevisionContent(ctx, store, pageId, *r.UUID)
+			if err != nil {
+				return false, err
+			}
+			diffBytes += int64(len(revDiff))
+		}
+	}
+
+	var age time.Duration
+	if policy.MaxAge > 0 {
+		snap, err := database.GetMostRecentSnapshot(ctx, db, revId)
+		if err != nil {
+			return false, err
+		}
+		if snap.Revision != nil {
+			snapRev, err := database.GetRevisionInfo(ctx, db, *snap.Revision)
+			if err != nil {
+				return false, err
+			}
+			last := missingRevs[len(missingRevs)-1]
+			age = last.DateTime.Sub(*snapRev.DateTime)
+		}
+	}
+
+	return policy.NeedsSnapshot(len(missingRevs), diffBytes, age), nil
+}
+
+// SnapshotPlanRevision is one revision of a page's history, oldest first, as
+// seen by PlanSnapshots.
+type SnapshotPlanRevision struct {
+	ID			uuid.UUID
+	DateTime	time.Time
+	DiffBytes	int64
+	Snapshots	[]uuid.UUID
+}
+
+// PlanSnapshots works out which snapshots a page's history should have under
+// the policy. The first revision always keeps one as the base for rebuilding;
+// after that a revision gets a snapshot when the chain since the last one is
+// due, and existing snapshots elsewhere (or duplicates at one revision) are
+// redundant. It returns the revisions that need a snapshot added and the
+// snapshots that can be dropped.
+func PlanSnapshots(revs []SnapshotPlanRevision, policy SnapshotPolicy) (add []uuid.UUID, drop []uuid.UUID) {
+	lastSnap := 0
+	var diffBytes int64
+	for i, rev := range revs {
+		keep := i == 0
+		if i > 0 {
+			diffBytes += rev.DiffBytes
+			keep = policy.NeedsSnapshot(i-lastSnap, diffBytes, rev.DateTime.Sub(revs[lastSnap].DateTime))
+		}
+
+		if !keep {
+			drop = append(drop, rev.Snapshots...)
+			continue
+		}
+		if len(rev.Snapshots) == 0 {
+			add = append(add, rev.ID)
+		} else {
+			drop = append(drop, rev.Snapshots[1:]...)
+		}
+		lastSnap = i
+		diffBytes = 0
+	}
+	return add, drop
+}
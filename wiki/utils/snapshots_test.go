@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func planHistory(n int, snapshotsAt ...int) []SnapshotPlanRevision {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	revs := make([]SnapshotPlanRevision, n)
+	for i := range revs {
+		revs[i] = SnapshotPlanRevision{ID: uuid.New(), DateTime: start.Add(time.Duration(i) * time.Hour), DiffBytes: 100}
+	}
+	for _, i := range snapshotsAt {
+		revs[i].Snapshots = append(revs[i].Snapshots, uuid.New())
+	}
+	return revs
+}
+
+func TestPlanSnapshotsEveryRevisions(t *testing.T) {
+	revs := planHistory(25, 0)
+	add, drop := PlanSnapshots(revs, SnapshotPolicy{EveryRevisions: 10})
+	if len(drop) != 0 {
+		t.Errorf("expected nothing dropped, got %d", len(drop))
+	}
+	if len(add) != 2 || add[0] != revs[10].ID || add[1] != revs[20].ID {
+		t.Errorf("expected snapshots added at revisions 10 and 20, got %v", add)
+	}
+}
+
+func TestPlanSnapshotsDropsRedundant(t *testing.T) {
+	revs := planHistory(12, 0, 0, 3, 10)
+	add, drop := PlanSnapshots(revs, SnapshotPolicy{EveryRevisions: 10})
+	if len(add) != 0 {
+		t.Errorf("expected nothing added, got %v", add)
+	}
+	want := map[uuid.UUID]bool{revs[0].Snapshots[1]: true, revs[3].Snapshots[0]: true}
+	if len(drop) != len(want) {
+		t.Fatalf("dropped %d snapshots, want %d", len(drop), len(want))
+	}
+	for _, id := range drop {
+		if !want[id] {
+			t.Errorf("unexpected snapshot dropped: %s", id)
+		}
+	}
+}
+
+func TestPlanSnapshotsBaseAndByteLimit(t *testing.T) {
+	revs := planHistory(6)
+	add, _ := PlanSnapshots(revs, SnapshotPolicy{MaxDiffBytes: 250})
+	// base at 0, then after 3 diffs of 100 bytes: 3, then 6 would be next
+	if len(add) != 2 || add[0] != revs[0].ID || add[1] != revs[3].ID {
+		t.Errorf("expected snapshots at revisions 0 and 3, got %v", add)
+	}
+}
+
+func TestPlanSnapshotsMaxAge(t *testing.T) {
+	revs := planHistory(5, 0)
+	add, _ := PlanSnapshots(revs, SnapshotPolicy{MaxAge: 2 * time.Hour})
+	if len(add) != 2 || add[0] != revs[2].ID || add[1] != revs[4].ID {
+		t.Errorf("expected snapshots at revisions 2 and 4, got %v", add)
+	}
+}
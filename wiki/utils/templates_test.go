@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func mapLookup(templates map[string]string) TemplateLookup {
+	return func(slug string) (string, bool, error) {
+		content, ok := templates[slug]
+		return content, ok, nil
+	}
+}
+
+func TestParseTemplateIncludes(t *testing.T) {
+	content := "{{Office Hours|room=Boone 210}}\n{{template-contact|email={{Email Box}}}}\n" +
+		"{{Office Hours}} and a {{{param}}} and `{{Code}}`\n```\n{{Fenced}}\n```\n"
+
+	got := ParseTemplateIncludes(content)
+	want := []string{"template-contact", "template-email-box", "template-office-hours"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestExpandTemplates(t *testing.T) {
+	lookup := mapLookup(map[string]string{
+		"template-office-hours": "Office hours in {{{room|TBA}}} on {{{1}}}.",
+		"template-contact":      "Contact: {{{name}}} ([[{{{page}}}|profile]])",
+		"template-box":          "> {{{1}}}",
+	})
+
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"{{Office Hours|room=Boone 210|Mondays}}", "Office hours in Boone 210 on Mondays."},
+		{"{{office-hours}}", "Office hours in TBA on ."},
+		{"{{template-contact|name=Dan|page=dan-boone}}", "Contact: Dan ([[dan-boone|profile]])"},
+		{"{{box|see [[dan-boone|Dan]]}}", "> see [[dan-boone|Dan]]"},
+		{"{{box|{{Office Hours|Fridays}}}}", "> Office hours in TBA on Fridays."},
+		{"`{{box|code}}` stays", "`{{box|code}}` stays"},
+		{"{{{1}}} isn't an include", "{{{1}}} isn't an include"},
+		{"{{unclosed", "{{unclosed"},
+	}
+	for _, tt := range tests {
+		got, err := ExpandTemplates(tt.content, lookup)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("ExpandTemplates(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestExpandTemplatesMissing(t *testing.T) {
+	got, err := ExpandTemplates("{{Nope}}", mapLookup(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `class="template-missing"`) || !strings.Contains(got, "slug=template-nope") {
+		t.Errorf("got %q, want a link to create template-nope", got)
+	}
+}
+
+func TestExpandTemplatesLoop(t *testing.T) {
+	lookup := mapLookup(map[string]string{
+		"template-a": "a {{b}}",
+		"template-b": "b {{a}}",
+	})
+	got, err := ExpandTemplates("{{a}}", lookup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "a b ") || !strings.Contains(got, "template loop: template-a") {
+		t.Errorf("got %q, want the loop reported where template-a includes itself", got)
+	}
+}
+
+func TestExpandTemplatesDepth(t *testing.T) {
+	templates := map[string]string{}
+	for i := range MaxTemplateDepth + 2 {
+		templates["template-"+string(rune('a'+i))] = string(rune('a'+i)) + "{{" + string(rune('a'+i+1)) + "}}"
+	}
+	got, err := ExpandTemplates("{{a}}", mapLookup(templates))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "abcde") || !strings.Contains(got, "template-f is nested more than 5 templates deep") {
+		t.Errorf("got %q, want expansion to stop %d templates deep", got, MaxTemplateDepth)
+	}
+}